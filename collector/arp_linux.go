@@ -0,0 +1,173 @@
+package collector
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/jsimonetti/rtnetlink"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/unix"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	arpSubsystem = "arp"
+)
+
+var (
+	arpDeviceInclude = flag.String("collector.arp.device-include", "", "Regexp of arp devices to include (mutually exclusive to device-exclude).")
+	arpDeviceExclude = flag.String("collector.arp.device-exclude", "", "Regexp of arp devices to exclude (mutually exclusive to device-include).")
+	arpNetlink       = flag.Bool("collector.arp.netlink", true, "Use netlink RTM_GETNEIGH to gather IPv4 and IPv6 neighbor states. When disabled, only the IPv4 entries count is read from /proc/net/arp and no states are exported.")
+
+	// arpStates are the neighbor states of include/uapi/linux/neighbour.h.
+	arpStates = map[uint16]string{
+		unix.NUD_INCOMPLETE: "incomplete",
+		unix.NUD_REACHABLE:  "reachable",
+		unix.NUD_STALE:      "stale",
+		unix.NUD_DELAY:      "delay",
+		unix.NUD_PROBE:      "probe",
+		unix.NUD_FAILED:     "failed",
+		unix.NUD_NOARP:      "noarp",
+		unix.NUD_PERMANENT:  "permanent",
+	}
+)
+
+// arpDevice identifies the neighbors of one address family on a device.
+type arpDevice struct {
+	device string
+	family string
+}
+
+type arpCollector struct {
+	deviceFilter deviceFilter
+	entries      *prometheus.Desc
+	states       *prometheus.Desc
+}
+
+func init() {
+	registerCollector(arpSubsystem, defaultEnabled, NewARPCollector)
+}
+
+// NewARPCollector returns a new Collector exposing the IPv4 and IPv6 neighbor
+// tables.
+func NewARPCollector() (Collector, error) {
+	if *arpDeviceExclude != "" && *arpDeviceInclude != "" {
+		return nil, errors.New("device-exclude & device-include are mutually exclusive")
+	}
+	return &arpCollector{
+		deviceFilter: newDeviceFilter(*arpDeviceExclude, *arpDeviceInclude),
+		entries: prometheus.NewDesc(prometheus.BuildFQName(namespace, arpSubsystem, "entries"),
+			"ARP entries by device and address family.", []string{"device", "family"}, nil,
+		),
+		states: prometheus.NewDesc(prometheus.BuildFQName(namespace, arpSubsystem, "states"),
+			"ARP entries by device, address family and state.", []string{"device", "family", "state"}, nil,
+		),
+	}, nil
+}
+
+func (c *arpCollector) Update(ch chan<- prometheus.Metric) error {
+	if !*arpNetlink {
+		entries, err := readARPEntries()
+		if err != nil {
+			return fmt.Errorf("could not get ARP entries: %w", err)
+		}
+		for device, count := range entries {
+			if c.deviceFilter.ignored(device) {
+				continue
+			}
+			ch <- prometheus.MustNewConstMetric(c.entries, prometheus.GaugeValue, count, device, routeFamily(unix.AF_INET))
+		}
+		return nil
+	}
+
+	states, err := getNeighborStates()
+	if err != nil {
+		return fmt.Errorf("could not get ARP entries: %w", err)
+	}
+	for d, deviceStates := range states {
+		if c.deviceFilter.ignored(d.device) {
+			continue
+		}
+		var entries float64
+		for state, count := range deviceStates {
+			entries += count
+			ch <- prometheus.MustNewConstMetric(c.states, prometheus.GaugeValue, count, d.device, d.family, state)
+		}
+		ch <- prometheus.MustNewConstMetric(c.entries, prometheus.GaugeValue, entries, d.device, d.family)
+	}
+	return nil
+}
+
+// getNeighborStates returns the number of IPv4 and IPv6 neighbors per device,
+// family and state.
+func getNeighborStates() (map[arpDevice]map[string]float64, error) {
+	conn, err := rtnetlink.Dial(nil)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	neighbors, err := conn.Neigh.List()
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := linkNames(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	states := map[arpDevice]map[string]float64{}
+	for _, n := range neighbors {
+		if n.Family != unix.AF_INET && n.Family != unix.AF_INET6 {
+			continue
+		}
+		device, ok := names[n.Index]
+		if !ok {
+			// The interface may have been removed since the dump.
+			continue
+		}
+
+		state, ok := arpStates[n.State]
+		if !ok {
+			state = "unknown"
+		}
+		d := arpDevice{device: device, family: routeFamily(uint8(n.Family))}
+		if states[d] == nil {
+			states[d] = map[string]float64{}
+		}
+		states[d][state]++
+	}
+	return states, nil
+}
+
+func readARPEntries() (map[string]float64, error) {
+	file, err := os.Open(procFilePath("net/arp"))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return parseARPEntries(file)
+}
+
+// parseARPEntries counts the entries per device of /proc/net/arp, whose
+// last column is the device.
+func parseARPEntries(r io.Reader) (map[string]float64, error) {
+	scanner := bufio.NewScanner(r)
+	entries := map[string]float64{}
+
+	// Skip the header line.
+	scanner.Scan()
+	for scanner.Scan() {
+		columns := strings.Fields(scanner.Text())
+		if len(columns) < 6 {
+			return nil, fmt.Errorf("unexpected ARP table format: %q", scanner.Text())
+		}
+		entries[columns[len(columns)-1]]++
+	}
+	return entries, scanner.Err()
+}
@@ -0,0 +1,156 @@
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/procfs/blockdevice"
+	"github.com/rs/zerolog/log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// blockQueueAttribute is a numeric /sys/block/<dev>/queue file exported as
+// gauge, with the factor converting it to the unit of the metric.
+type blockQueueAttribute struct {
+	file  string
+	scale float64
+	desc  *prometheus.Desc
+}
+
+type blockQueueCollector struct {
+	deviceFilter deviceFilter
+	fs           blockdevice.FS
+	attributes   []blockQueueAttribute
+	scheduler    *prometheus.Desc
+	inflight     *prometheus.Desc
+}
+
+func init() {
+	registerCollector("blockqueue", defaultEnabled, NewBlockQueueCollector)
+}
+
+// NewBlockQueueCollector returns a new Collector exposing the request queue
+// settings and in flight requests of block devices.
+// Docs from https://www.kernel.org/doc/Documentation/block/queue-sysfs.txt
+func NewBlockQueueCollector() (Collector, error) {
+	fs, err := blockdevice.NewFS(*procPath, *sysPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sysfs: %w", err)
+	}
+
+	deviceFilter, err := newDiskstatsDeviceFilter()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse device filter flags: %w", err)
+	}
+
+	attribute := func(file string, scale float64, name, help string) blockQueueAttribute {
+		return blockQueueAttribute{
+			file:  file,
+			scale: scale,
+			desc:  prometheus.NewDesc(prometheus.BuildFQName(namespace, diskSubsystem, name), help, diskLabelNames, nil),
+		}
+	}
+
+	return &blockQueueCollector{
+		deviceFilter: deviceFilter,
+		fs:           fs,
+		attributes: []blockQueueAttribute{
+			attribute("nr_requests", 1, "queue_nr_requests",
+				"Number of requests that may be allocated for reads or writes of the device."),
+			attribute("rotational", 1, "queue_rotational",
+				"Whether the device is of rotational type."),
+			attribute("logical_block_size", 1, "queue_logical_block_size_bytes",
+				"Logical block size of the device."),
+			attribute("physical_block_size", 1, "queue_physical_block_size_bytes",
+				"Physical block size of the device."),
+			attribute("discard_max_bytes", 1, "queue_discard_max_bytes",
+				"Maximum number of bytes discarded in a single operation, 0 if the device does not support discard."),
+			attribute("read_ahead_kb", 1024, "queue_read_ahead_bytes",
+				"Maximum number of bytes to read-ahead for filesystems on the device."),
+			attribute("max_sectors_kb", 1024, "queue_max_sectors_bytes",
+				"Maximum size of a filesystem request to the device."),
+		},
+		scheduler: prometheus.NewDesc(prometheus.BuildFQName(namespace, diskSubsystem, "queue_scheduler"),
+			"Whether the I/O scheduler is the active one of the device.", []string{"device", "scheduler"}, nil,
+		),
+		inflight: prometheus.NewDesc(prometheus.BuildFQName(namespace, diskSubsystem, "inflight_requests"),
+			"Number of requests issued to the device driver but not yet completed.", []string{"device", "operation"}, nil,
+		),
+	}, nil
+}
+
+func (c *blockQueueCollector) Update(ch chan<- prometheus.Metric) error {
+	devices, err := c.fs.SysBlockDevices()
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			log.Debug().Msg("block devices are not available for this system")
+			return ErrNoData
+		}
+		return fmt.Errorf("couldn't get block devices: %w", err)
+	}
+
+	for _, dev := range devices {
+		if c.deviceFilter.ignored(dev) {
+			continue
+		}
+		c.updateDevice(ch, dev)
+	}
+	return nil
+}
+
+// updateDevice exports the queue attributes present for the device. The set
+// of files below queue depends on the kernel version and the driver, so
+// missing ones are skipped instead of failing the device.
+func (c *blockQueueCollector) updateDevice(ch chan<- prometheus.Metric, dev string) {
+	devPath := sysFilePath(filepath.Join("block", dev))
+	queuePath := filepath.Join(devPath, "queue")
+
+	if data, err := os.ReadFile(filepath.Join(queuePath, "scheduler")); err == nil {
+		current, schedulers := parseBlockScheduler(string(data))
+		for _, s := range schedulers {
+			ch <- prometheus.MustNewConstMetric(c.scheduler, prometheus.GaugeValue, boolToFloat(s == current), dev, s)
+		}
+	}
+
+	for _, a := range c.attributes {
+		value, err := readUintFromFile(filepath.Join(queuePath, a.file))
+		if err != nil {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(a.desc, prometheus.GaugeValue, float64(value)*a.scale, dev)
+	}
+
+	// inflight holds the number of in flight reads and writes, e.g. "1 3".
+	data, err := os.ReadFile(filepath.Join(devPath, "inflight"))
+	if err != nil {
+		return
+	}
+	var reads, writes uint64
+	if _, err := fmt.Sscan(string(data), &reads, &writes); err != nil {
+		log.Debug().Err(err).Msgf("couldn't parse inflight of %s", dev)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(c.inflight, prometheus.GaugeValue, float64(reads), dev, "read")
+	ch <- prometheus.MustNewConstMetric(c.inflight, prometheus.GaugeValue, float64(writes), dev, "write")
+}
+
+// parseBlockScheduler returns the active and the available schedulers of a
+// queue/scheduler file like "none [mq-deadline] kyber".
+func parseBlockScheduler(data string) (string, []string) {
+	var current string
+	schedulers := strings.Fields(data)
+	for i, s := range schedulers {
+		if strings.HasPrefix(s, "[") && strings.HasSuffix(s, "]") {
+			s = s[1 : len(s)-1]
+			schedulers[i] = s
+			current = s
+		}
+	}
+	// Devices without scheduler support only report "none".
+	if current == "" && len(schedulers) == 1 {
+		current = schedulers[0]
+	}
+	return current, schedulers
+}
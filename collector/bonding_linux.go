@@ -0,0 +1,216 @@
+package collector
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog/log"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	bondingSubsystem = "bonding"
+)
+
+type bondingCollector struct {
+	slaves            *prometheus.Desc
+	active            *prometheus.Desc
+	info              *prometheus.Desc
+	miiStatus         *prometheus.Desc
+	slaveMiiStatus    *prometheus.Desc
+	slaveActive       *prometheus.Desc
+	slaveLinkFailures *prometheus.Desc
+}
+
+// bondingSlave is the state of a bond slave from sysfs and /proc/net/bonding.
+type bondingSlave struct {
+	miiStatus    string
+	state        string
+	linkFailures *uint64
+}
+
+type bondingMaster struct {
+	mode      string
+	miiStatus string
+	slaves    map[string]*bondingSlave
+}
+
+func init() {
+	registerCollector(bondingSubsystem, defaultEnabled, NewBondingCollector)
+}
+
+// NewBondingCollector returns a new Collector exposing bonding interface
+// status and slave link state.
+// Docs from https://www.kernel.org/doc/Documentation/networking/bonding.txt
+func NewBondingCollector() (Collector, error) {
+	return &bondingCollector{
+		slaves: prometheus.NewDesc(prometheus.BuildFQName(namespace, bondingSubsystem, "slaves"),
+			"Number of configured slaves per bonding interface.", []string{"master"}, nil,
+		),
+		active: prometheus.NewDesc(prometheus.BuildFQName(namespace, bondingSubsystem, "active"),
+			"Number of slaves with MII status up per bonding interface.", []string{"master"}, nil,
+		),
+		info: prometheus.NewDesc(prometheus.BuildFQName(namespace, bondingSubsystem, "info"),
+			"Mode of the bonding interface, value is always 1.", []string{"master", "mode"}, nil,
+		),
+		miiStatus: prometheus.NewDesc(prometheus.BuildFQName(namespace, bondingSubsystem, "mii_status"),
+			"Whether the MII status of the bonding interface is up.", []string{"master"}, nil,
+		),
+		slaveMiiStatus: prometheus.NewDesc(prometheus.BuildFQName(namespace, bondingSubsystem, "slave_mii_status"),
+			"Whether the MII status of the slave is up.", []string{"master", "slave"}, nil,
+		),
+		slaveActive: prometheus.NewDesc(prometheus.BuildFQName(namespace, bondingSubsystem, "slave_active"),
+			"Whether the slave is active rather than backup.", []string{"master", "slave"}, nil,
+		),
+		slaveLinkFailures: prometheus.NewDesc(prometheus.BuildFQName(namespace, bondingSubsystem, "slave_link_failures_total"),
+			"Number of times the link of the slave has failed.", []string{"master", "slave"}, nil,
+		),
+	}, nil
+}
+
+func (c *bondingCollector) Update(ch chan<- prometheus.Metric) error {
+	masters, err := readBondingMasters()
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			log.Debug().Msg("Not collecting bonding, file does not exist")
+			return ErrNoData
+		}
+		return err
+	}
+
+	for name, master := range masters {
+		var active float64
+		for slaveName, slave := range master.slaves {
+			if slave.miiStatus == "up" {
+				active++
+			}
+			ch <- prometheus.MustNewConstMetric(c.slaveMiiStatus, prometheus.GaugeValue, boolToFloat(slave.miiStatus == "up"), name, slaveName)
+			if slave.state != "" {
+				ch <- prometheus.MustNewConstMetric(c.slaveActive, prometheus.GaugeValue, boolToFloat(slave.state == "active"), name, slaveName)
+			}
+			if slave.linkFailures != nil {
+				ch <- prometheus.MustNewConstMetric(c.slaveLinkFailures, prometheus.CounterValue, float64(*slave.linkFailures), name, slaveName)
+			}
+		}
+		ch <- prometheus.MustNewConstMetric(c.slaves, prometheus.GaugeValue, float64(len(master.slaves)), name)
+		ch <- prometheus.MustNewConstMetric(c.active, prometheus.GaugeValue, active, name)
+		ch <- prometheus.MustNewConstMetric(c.info, prometheus.GaugeValue, 1, name, master.mode)
+		ch <- prometheus.MustNewConstMetric(c.miiStatus, prometheus.GaugeValue, boolToFloat(master.miiStatus == "up"), name)
+	}
+	return nil
+}
+
+// readBondingMasters reads the bonds listed in /sys/class/net/bonding_masters
+// and their slaves, adding the link failure counts of /proc/net/bonding.
+func readBondingMasters() (map[string]*bondingMaster, error) {
+	netPath := filepath.Join(*sysPath, "class", "net")
+	data, err := os.ReadFile(filepath.Join(netPath, "bonding_masters"))
+	if err != nil {
+		return nil, err
+	}
+
+	masters := map[string]*bondingMaster{}
+	for _, name := range strings.Fields(string(data)) {
+		bondingPath := filepath.Join(netPath, name, "bonding")
+		slaves, err := os.ReadFile(filepath.Join(bondingPath, "slaves"))
+		if err != nil {
+			log.Debug().Err(err).Msgf("couldn't read slaves of bond %s", name)
+			continue
+		}
+
+		// mode is like "802.3ad 4".
+		mode, _ := readStringFromFile(filepath.Join(bondingPath, "mode"))
+		miiStatus, _ := readStringFromFile(filepath.Join(bondingPath, "mii_status"))
+		master := &bondingMaster{
+			mode:      firstField(mode),
+			miiStatus: miiStatus,
+			slaves:    map[string]*bondingSlave{},
+		}
+		for _, slaveName := range strings.Fields(string(slaves)) {
+			slavePath := filepath.Join(netPath, slaveName, "bonding_slave")
+			miiStatus, _ := readStringFromFile(filepath.Join(slavePath, "mii_status"))
+			state, _ := readStringFromFile(filepath.Join(slavePath, "state"))
+			slave := &bondingSlave{
+				miiStatus: miiStatus,
+				state:     state,
+			}
+			if failures, err := readUintFromFile(filepath.Join(slavePath, "link_failure_count")); err == nil {
+				slave.linkFailures = &failures
+			}
+			master.slaves[slaveName] = slave
+		}
+
+		if err := readProcBonding(name, master); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Debug().Err(err).Msgf("couldn't read /proc/net/bonding/%s", name)
+		}
+		masters[name] = master
+	}
+	return masters, nil
+}
+
+// readProcBonding fills in the slave state missing from sysfs on older
+// kernels with /proc/net/bonding/<bond>.
+func readProcBonding(name string, master *bondingMaster) error {
+	file, err := os.Open(procFilePath(filepath.Join("net", "bonding", name)))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	slaves, err := parseProcBonding(file)
+	if err != nil {
+		return err
+	}
+	for slaveName, s := range slaves {
+		slave, ok := master.slaves[slaveName]
+		if !ok {
+			continue
+		}
+		if slave.miiStatus == "" {
+			slave.miiStatus = s.miiStatus
+		}
+		if slave.linkFailures == nil {
+			slave.linkFailures = s.linkFailures
+		}
+	}
+	return nil
+}
+
+// parseProcBonding parses the per slave sections of /proc/net/bonding/<bond>,
+// which start with "Slave Interface: <name>".
+func parseProcBonding(r io.Reader) (map[string]*bondingSlave, error) {
+	slaves := map[string]*bondingSlave{}
+	var current *bondingSlave
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "Slave Interface":
+			current = &bondingSlave{}
+			slaves[value] = current
+		case "MII Status":
+			if current != nil {
+				current.miiStatus = value
+			}
+		case "Link Failure Count":
+			if current != nil {
+				failures, err := strconv.ParseUint(value, 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("invalid link failure count %q: %w", value, err)
+				}
+				current.linkFailures = &failures
+			}
+		}
+	}
+	return slaves, scanner.Err()
+}
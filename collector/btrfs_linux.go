@@ -0,0 +1,155 @@
+package collector
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/procfs/btrfs"
+	"github.com/rs/zerolog/log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	btrfsSubsystem = "btrfs"
+)
+
+type btrfsCollector struct {
+	fs btrfs.FS
+
+	info            *prometheus.Desc
+	globalRsvSize   *prometheus.Desc
+	reserved        *prometheus.Desc
+	used            *prometheus.Desc
+	size            *prometheus.Desc
+	allocationRatio *prometheus.Desc
+	deviceSize      *prometheus.Desc
+	deviceErrors    *prometheus.Desc
+}
+
+func init() {
+	registerCollector(btrfsSubsystem, defaultEnabled, NewBtrfsCollector)
+}
+
+// NewBtrfsCollector returns a new Collector exposing the allocation and device
+// error statistics of the mounted btrfs filesystems of /sys/fs/btrfs/<uuid>.
+func NewBtrfsCollector() (Collector, error) {
+	fs, err := btrfs.NewFS(*sysPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sysfs: %w", err)
+	}
+
+	return &btrfsCollector{
+		fs: fs,
+		info: prometheus.NewDesc(prometheus.BuildFQName(namespace, btrfsSubsystem, "info"),
+			"Filesystem information, value is always 1.", []string{"uuid", "label"}, nil,
+		),
+		globalRsvSize: prometheus.NewDesc(prometheus.BuildFQName(namespace, btrfsSubsystem, "global_rsv_size_bytes"),
+			"Size of the global reserve.", []string{"uuid"}, nil,
+		),
+		reserved: prometheus.NewDesc(prometheus.BuildFQName(namespace, btrfsSubsystem, "reserved_bytes"),
+			"Amount of space reserved for a data type.", []string{"uuid", "block_group_type"}, nil,
+		),
+		used: prometheus.NewDesc(prometheus.BuildFQName(namespace, btrfsSubsystem, "used_bytes"),
+			"Amount of used space by a layout/data type.", []string{"uuid", "block_group_type", "mode"}, nil,
+		),
+		size: prometheus.NewDesc(prometheus.BuildFQName(namespace, btrfsSubsystem, "size_bytes"),
+			"Amount of space allocated for a layout/data type.", []string{"uuid", "block_group_type", "mode"}, nil,
+		),
+		allocationRatio: prometheus.NewDesc(prometheus.BuildFQName(namespace, btrfsSubsystem, "allocation_ratio"),
+			"Data allocation ratio for a layout/data type.", []string{"uuid", "block_group_type", "mode"}, nil,
+		),
+		deviceSize: prometheus.NewDesc(prometheus.BuildFQName(namespace, btrfsSubsystem, "device_size_bytes"),
+			"Size of a device that is part of the filesystem.", []string{"uuid", "device"}, nil,
+		),
+		deviceErrors: prometheus.NewDesc(prometheus.BuildFQName(namespace, btrfsSubsystem, "device_errors_total"),
+			"Errors reported for a device of the filesystem.", []string{"uuid", "devid", "type"}, nil,
+		),
+	}, nil
+}
+
+func (c *btrfsCollector) Update(ch chan<- prometheus.Metric) error {
+	stats, err := c.fs.Stats()
+	if err != nil {
+		return fmt.Errorf("failed to retrieve Btrfs stats: %w", err)
+	}
+	if len(stats) == 0 {
+		return ErrNoData
+	}
+
+	for _, s := range stats {
+		ch <- prometheus.MustNewConstMetric(c.info, prometheus.GaugeValue, 1, s.UUID, s.Label)
+		ch <- prometheus.MustNewConstMetric(c.globalRsvSize, prometheus.GaugeValue, float64(s.Allocation.GlobalRsvSize), s.UUID)
+
+		for blockGroupType, a := range map[string]*btrfs.AllocationStats{
+			"data":     s.Allocation.Data,
+			"metadata": s.Allocation.Metadata,
+			"system":   s.Allocation.System,
+		} {
+			if a == nil {
+				continue
+			}
+			ch <- prometheus.MustNewConstMetric(c.reserved, prometheus.GaugeValue, float64(a.ReservedBytes), s.UUID, blockGroupType)
+			for mode, l := range a.Layouts {
+				ch <- prometheus.MustNewConstMetric(c.used, prometheus.GaugeValue, float64(l.UsedBytes), s.UUID, blockGroupType, mode)
+				ch <- prometheus.MustNewConstMetric(c.size, prometheus.GaugeValue, float64(l.TotalBytes), s.UUID, blockGroupType, mode)
+				ch <- prometheus.MustNewConstMetric(c.allocationRatio, prometheus.GaugeValue, l.Ratio, s.UUID, blockGroupType, mode)
+			}
+		}
+
+		for device, d := range s.Devices {
+			ch <- prometheus.MustNewConstMetric(c.deviceSize, prometheus.GaugeValue, float64(d.Size), s.UUID, device)
+		}
+
+		if err := c.updateDeviceErrors(ch, s.UUID); err != nil {
+			log.Debug().Err(err).Msgf("couldn't read device errors of btrfs %s", s.UUID)
+		}
+	}
+	return nil
+}
+
+// updateDeviceErrors exports devinfo/<devid>/error_stats, available since
+// Linux 5.14, which holds lines like "write_errs 0".
+func (c *btrfsCollector) updateDeviceErrors(ch chan<- prometheus.Metric, uuid string) error {
+	paths, err := filepath.Glob(sysFilePath(filepath.Join("fs", "btrfs", uuid, "devinfo", "*", "error_stats")))
+	if err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		devid := filepath.Base(filepath.Dir(path))
+		errs, err := parseBtrfsErrorStats(path)
+		if err != nil {
+			return err
+		}
+		for errType, value := range errs {
+			ch <- prometheus.MustNewConstMetric(c.deviceErrors, prometheus.CounterValue, float64(value), uuid, devid, errType)
+		}
+	}
+	return nil
+}
+
+func parseBtrfsErrorStats(path string) (map[string]uint64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	errs := map[string]uint64{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		value, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value in %s: %w", path, err)
+		}
+		errs[strings.TrimSuffix(fields[0], "_errs")] = value
+	}
+	return errs, scanner.Err()
+}
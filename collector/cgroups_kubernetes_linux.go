@@ -0,0 +1,201 @@
+package collector
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// kubernetesContainerDepth is the depth of the container cgroups of
+	// burstable and besteffort pods, e.g.
+	// /kubepods/besteffort/pod<uid>/<id>, down to which the kubepods cgroups
+	// are walked independent of --collector.cgroups.max-depth.
+	kubernetesContainerDepth = 4
+)
+
+var (
+	cgroupsKubernetes         = flag.Bool("collector.cgroups.kubernetes", false, "Add pod_uid, qos_class, container_id, pod and namespace labels to cgroups of kubelet managed pods.")
+	cgroupsKubernetesPodsFile = flag.String("collector.cgroups.kubernetes.pods-file", "", "Local JSON file with the pods of this node, in the format of the kubelet /pods endpoint or a kubelet pod checkpoint, used to add pod and namespace labels.")
+
+	kubernetesCgroupLabels = []string{"pod_uid", "qos_class", "container_id", "pod", "namespace"}
+
+	// Pod cgroups are named kubepods-<qos>-pod<uid>.slice with the systemd
+	// cgroup driver, where the dashes of the uid are replaced by underscores,
+	// and pod<uid> with the cgroupfs driver.
+	kubernetesPodPattern = regexp.MustCompile(`^(?:kubepods-(?:burstable-|besteffort-)?)?pod([0-9a-f]{8}[-_][0-9a-f]{4}[-_][0-9a-f]{4}[-_][0-9a-f]{4}[-_][0-9a-f]{12})(?:\.slice)?$`)
+	// Container cgroups are named <runtime>-<id>.scope with the systemd cgroup
+	// driver and <id> with the cgroupfs driver.
+	kubernetesContainerPattern = regexp.MustCompile(`^(?:(?:cri-containerd|crio|docker|containerd)-)?([0-9a-f]{64})(?:\.scope)?$`)
+)
+
+// kubernetesCgroup is the pod and container a cgroup path belongs to.
+type kubernetesCgroup struct {
+	podUID      string
+	qosClass    string
+	containerID string
+}
+
+// isKubernetesCgroup returns whether a cgroup path is below the kubepods
+// cgroup of the systemd or the cgroupfs cgroup driver.
+func isKubernetesCgroup(path string) bool {
+	top, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	return top == "kubepods.slice" || top == "kubepods"
+}
+
+// parseKubernetesCgroup derives the pod uid, QoS class and container id of a
+// cgroup path like
+// /kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod<uid>.slice/cri-containerd-<id>.scope
+// or /kubepods/besteffort/pod<uid>/<id>.
+func parseKubernetesCgroup(path string) (kubernetesCgroup, bool) {
+	var k kubernetesCgroup
+	if !isKubernetesCgroup(path) {
+		return k, false
+	}
+	elements := strings.Split(strings.Trim(path, "/"), "/")
+
+	for i, element := range elements[1:] {
+		if m := kubernetesPodPattern.FindStringSubmatch(element); m != nil {
+			k.podUID = strings.ReplaceAll(m[1], "_", "-")
+			continue
+		}
+		// The container is the cgroup directly below the pod.
+		if k.podUID != "" && k.containerID == "" && i == len(elements)-2 {
+			if m := kubernetesContainerPattern.FindStringSubmatch(element); m != nil {
+				k.containerID = m[1]
+			}
+		}
+	}
+	if k.podUID == "" {
+		return k, false
+	}
+
+	// Burstable and besteffort pods are grouped in a kubepods-<qos>.slice or
+	// kubepods/<qos> cgroup, guaranteed pods are directly below kubepods.
+	switch strings.TrimSuffix(strings.TrimPrefix(elements[1], "kubepods-"), ".slice") {
+	case "burstable":
+		k.qosClass = "burstable"
+	case "besteffort":
+		k.qosClass = "besteffort"
+	default:
+		k.qosClass = "guaranteed"
+	}
+	return k, true
+}
+
+type kubernetesPod struct {
+	name      string
+	namespace string
+}
+
+// kubernetesPods is the pod uid to name and namespace mapping read from
+// --collector.cgroups.kubernetes.pods-file, reloaded when the file changes.
+type kubernetesPods struct {
+	path    string
+	mutex   sync.Mutex
+	modTime time.Time
+	pods    map[string]kubernetesPod
+}
+
+type kubernetesPodMetadata struct {
+	UID       string `json:"uid"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+type kubernetesPodJSON struct {
+	Metadata kubernetesPodMetadata `json:"metadata"`
+}
+
+// kubernetesPodsJSON covers a pod list like the kubelet /pods endpoint, a
+// single pod and a kubelet pod checkpoint, which stores the pod under Pod.
+type kubernetesPodsJSON struct {
+	Items    []kubernetesPodJSON   `json:"items"`
+	Metadata kubernetesPodMetadata `json:"metadata"`
+	Pod      *kubernetesPodJSON    `json:"pod"`
+}
+
+func newKubernetesPods(path string) *kubernetesPods {
+	return &kubernetesPods{
+		path: path,
+		pods: map[string]kubernetesPod{},
+	}
+}
+
+// lookup returns the pod with the given uid, or an empty pod when there is no
+// pods file or the pod is not listed in it.
+func (p *kubernetesPods) lookup(uid string) kubernetesPod {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.pods[uid]
+}
+
+// update reloads the pods file when it changed, it is called once per scrape.
+func (p *kubernetesPods) update() {
+	if p.path == "" {
+		return
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if err := p.reload(); err != nil {
+		log.Warn().Err(err).Msgf("couldn't read kubernetes pods file %s", p.path)
+	}
+}
+
+func (p *kubernetesPods) reload() error {
+	info, err := os.Stat(p.path)
+	if err != nil {
+		return err
+	}
+	if info.ModTime().Equal(p.modTime) {
+		return nil
+	}
+
+	data, err := os.ReadFile(p.path)
+	if err != nil {
+		return err
+	}
+	pods, err := parseKubernetesPods(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", p.path, err)
+	}
+	p.pods = pods
+	p.modTime = info.ModTime()
+	return nil
+}
+
+func parseKubernetesPods(data []byte) (map[string]kubernetesPod, error) {
+	var list kubernetesPodsJSON
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+
+	items := list.Items
+	if list.Metadata.UID != "" {
+		items = append(items, kubernetesPodJSON{Metadata: list.Metadata})
+	}
+	if list.Pod != nil {
+		items = append(items, *list.Pod)
+	}
+
+	pods := make(map[string]kubernetesPod, len(items))
+	for _, item := range items {
+		if item.Metadata.UID == "" {
+			continue
+		}
+		pods[item.Metadata.UID] = kubernetesPod{
+			name:      item.Metadata.Name,
+			namespace: item.Metadata.Namespace,
+		}
+	}
+	return pods, nil
+}
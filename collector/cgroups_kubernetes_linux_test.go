@@ -0,0 +1,126 @@
+package collector
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseKubernetesCgroup(t *testing.T) {
+	const containerID = "0f1e2d3c4b5a69780f1e2d3c4b5a69780f1e2d3c4b5a69780f1e2d3c4b5a6978"
+	tests := []struct {
+		path string
+		ok   bool
+		want kubernetesCgroup
+	}{
+		{"/system.slice/sshd.service", false, kubernetesCgroup{}},
+		{"/kubepods.slice/kubepods-burstable.slice", false, kubernetesCgroup{}},
+		{
+			"/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod1b2c3d4e_0000_1111_2222_333344445555.slice",
+			true,
+			kubernetesCgroup{podUID: "1b2c3d4e-0000-1111-2222-333344445555", qosClass: "burstable"},
+		},
+		{
+			"/kubepods.slice/kubepods-pod1b2c3d4e_0000_1111_2222_333344445555.slice/cri-containerd-" + containerID + ".scope",
+			true,
+			kubernetesCgroup{podUID: "1b2c3d4e-0000-1111-2222-333344445555", qosClass: "guaranteed", containerID: containerID},
+		},
+		{
+			"/kubepods/besteffort/pod1b2c3d4e-0000-1111-2222-333344445555/" + containerID,
+			true,
+			kubernetesCgroup{podUID: "1b2c3d4e-0000-1111-2222-333344445555", qosClass: "besteffort", containerID: containerID},
+		},
+		{
+			"/kubepods/pod1b2c3d4e-0000-1111-2222-333344445555/" + containerID + "/burstable-worker",
+			true,
+			kubernetesCgroup{podUID: "1b2c3d4e-0000-1111-2222-333344445555", qosClass: "guaranteed"},
+		},
+	}
+	for _, tt := range tests {
+		got, ok := parseKubernetesCgroup(tt.path)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("%s: want %+v %v, got %+v %v", tt.path, tt.want, tt.ok, got, ok)
+		}
+	}
+}
+
+func TestParseKubernetesPods(t *testing.T) {
+	pods, err := parseKubernetesPods([]byte(`{"kind":"PodList","items":[{"metadata":{"uid":"a","name":"web-0","namespace":"prod"}},{"metadata":{"uid":"b","name":"db-0","namespace":"prod"}}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pods) != 2 || pods["a"] != (kubernetesPod{name: "web-0", namespace: "prod"}) {
+		t.Errorf("unexpected pods: %+v", pods)
+	}
+
+	pods, err = parseKubernetesPods([]byte(`{"Pod":{"metadata":{"uid":"c","name":"static","namespace":"kube-system"}},"Checksum":1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pods["c"] != (kubernetesPod{name: "static", namespace: "kube-system"}) {
+		t.Errorf("unexpected checkpoint pods: %+v", pods)
+	}
+}
+
+func TestCgroupsKubernetesWalk(t *testing.T) {
+	const (
+		podUID      = "1b2c3d4e-0000-1111-2222-333344445555"
+		containerID = "0f1e2d3c4b5a69780f1e2d3c4b5a69780f1e2d3c4b5a69780f1e2d3c4b5a6978"
+	)
+	root := t.TempDir()
+	cgroupRoot := filepath.Join(root, "fs", "cgroup")
+	for _, dir := range []string{
+		"kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod1b2c3d4e_0000_1111_2222_333344445555.slice/cri-containerd-" + containerID + ".scope",
+		"kubepods/besteffort/pod" + podUID + "/" + containerID,
+		"system.slice/containerd.service/a/b",
+	} {
+		if err := os.MkdirAll(filepath.Join(cgroupRoot, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(cgroupRoot, dir, "pids.current"), []byte("3\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(cgroupRoot, "cgroup.controllers"), []byte("cpu memory pids\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	oldSysPath, oldKubernetes := *sysPath, *cgroupsKubernetes
+	defer func() { *sysPath, *cgroupsKubernetes = oldSysPath, oldKubernetes }()
+	*sysPath = root
+	*cgroupsKubernetes = true
+
+	c, err := NewCgroupsCollector()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch := make(chan prometheus.Metric, 100)
+	if err := c.Update(ch); err != nil {
+		t.Fatal(err)
+	}
+	close(ch)
+
+	qosClasses := map[string]bool{}
+	for m := range ch {
+		var pb dto.Metric
+		if err := m.Write(&pb); err != nil {
+			t.Fatal(err)
+		}
+		labels := map[string]string{}
+		for _, l := range pb.GetLabel() {
+			labels[l.GetName()] = l.GetValue()
+		}
+		if strings.HasPrefix(labels["cgroup"], "/system.slice/containerd.service/a/b") {
+			t.Errorf("cgroup %s is deeper than --collector.cgroups.max-depth", labels["cgroup"])
+		}
+		if labels["container_id"] == containerID && labels["pod_uid"] == podUID {
+			qosClasses[labels["qos_class"]] = true
+		}
+	}
+	if !qosClasses["burstable"] || !qosClasses["besteffort"] {
+		t.Errorf("want container cgroups of burstable and besteffort pods, got %v", qosClasses)
+	}
+}
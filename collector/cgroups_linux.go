@@ -0,0 +1,292 @@
+package collector
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog/log"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	cgroupSubsystem = "cgroup"
+)
+
+var (
+	cgroupsMaxDepth    = flag.Int("collector.cgroups.max-depth", 3, "Maximum depth of the cgroup v2 hierarchy to walk, the root cgroup has depth 0. With --collector.cgroups.kubernetes the kubepods cgroups are walked down to the containers regardless.")
+	cgroupsPathInclude = flag.String("collector.cgroups.path-include", "", "Regexp of cgroup paths to include (mutually exclusive to path-exclude).")
+	cgroupsPathExclude = flag.String("collector.cgroups.path-exclude", "", "Regexp of cgroup paths to exclude (mutually exclusive to path-include).")
+
+	// cgroupMemoryStatFields are the byte sized fields of memory.stat to export.
+	cgroupMemoryStatFields = []string{"anon", "file", "kernel", "kernel_stack", "slab", "sock", "shmem", "file_mapped", "file_dirty", "file_writeback"}
+)
+
+type cgroupsCollector struct {
+	root       string
+	maxDepth   int
+	pathFilter deviceFilter
+	kubernetes bool
+	pods       *kubernetesPods
+
+	cpuUsage          *prometheus.Desc
+	cpuUser           *prometheus.Desc
+	cpuSystem         *prometheus.Desc
+	cpuPeriods        *prometheus.Desc
+	cpuThrottled      *prometheus.Desc
+	cpuThrottledTime  *prometheus.Desc
+	memoryCurrent     *prometheus.Desc
+	memoryStat        *prometheus.Desc
+	memoryPgfault     *prometheus.Desc
+	memoryPgmajfault  *prometheus.Desc
+	memoryEvents      *prometheus.Desc
+	ioReadBytes       *prometheus.Desc
+	ioWriteBytes      *prometheus.Desc
+	ioReads           *prometheus.Desc
+	ioWrites          *prometheus.Desc
+	pidsCurrent       *prometheus.Desc
+	blockDevicesMutex sync.Mutex
+	blockDevices      map[string]string
+}
+
+func init() {
+	registerCollector("cgroups", defaultDisabled, NewCgroupsCollector)
+}
+
+// NewCgroupsCollector returns a new Collector exposing cgroup v2 resource usage.
+// Docs from https://www.kernel.org/doc/Documentation/admin-guide/cgroup-v2.rst
+func NewCgroupsCollector() (Collector, error) {
+	if *cgroupsPathExclude != "" && *cgroupsPathInclude != "" {
+		return nil, errors.New("path-exclude & path-include are mutually exclusive")
+	}
+	if *cgroupsPathExclude != "" {
+		log.Info().Msgf("Parsed flag --collector.cgroups.path-exclude: %s", *cgroupsPathExclude)
+	}
+	if *cgroupsPathInclude != "" {
+		log.Info().Msgf("Parsed flag --collector.cgroups.path-include: %s", *cgroupsPathInclude)
+	}
+
+	labels := []string{"cgroup"}
+	if *cgroupsKubernetes {
+		labels = append(labels, kubernetesCgroupLabels...)
+	}
+	// Force appending the extra labels to copy instead of sharing labels.
+	labels = labels[:len(labels):len(labels)]
+	newDesc := func(name, help string, extraLabels ...string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, cgroupSubsystem, name), help, append(labels, extraLabels...), nil)
+	}
+	return &cgroupsCollector{
+		root:       filepath.Join(*sysPath, "fs", "cgroup"),
+		maxDepth:   *cgroupsMaxDepth,
+		pathFilter: newDeviceFilter(*cgroupsPathExclude, *cgroupsPathInclude),
+		kubernetes: *cgroupsKubernetes,
+		pods:       newKubernetesPods(*cgroupsKubernetesPodsFile),
+
+		cpuUsage:         newDesc("cpu_usage_seconds_total", "Total CPU time consumed by the cgroup."),
+		cpuUser:          newDesc("cpu_user_seconds_total", "User CPU time consumed by the cgroup."),
+		cpuSystem:        newDesc("cpu_system_seconds_total", "System CPU time consumed by the cgroup."),
+		cpuPeriods:       newDesc("cpu_periods_total", "Number of elapsed CPU bandwidth enforcement periods."),
+		cpuThrottled:     newDesc("cpu_throttled_periods_total", "Number of CPU bandwidth periods in which the cgroup was throttled."),
+		cpuThrottledTime: newDesc("cpu_throttled_seconds_total", "Total time the cgroup was throttled."),
+		memoryCurrent:    newDesc("memory_usage_bytes", "Total memory currently used by the cgroup and its descendants."),
+		memoryStat:       newDesc("memory_stat_bytes", "Memory usage of the cgroup broken down by type, from memory.stat.", "type"),
+		memoryPgfault:    newDesc("memory_page_faults_total", "Number of page faults incurred by the cgroup."),
+		memoryPgmajfault: newDesc("memory_major_page_faults_total", "Number of major page faults incurred by the cgroup."),
+		memoryEvents:     newDesc("memory_events_total", "Number of memory events of the cgroup, from memory.events.", "event"),
+		ioReadBytes:      newDesc("io_read_bytes_total", "Number of bytes read by the cgroup per device.", "device"),
+		ioWriteBytes:     newDesc("io_write_bytes_total", "Number of bytes written by the cgroup per device.", "device"),
+		ioReads:          newDesc("io_reads_total", "Number of read IOs of the cgroup per device.", "device"),
+		ioWrites:         newDesc("io_writes_total", "Number of write IOs of the cgroup per device.", "device"),
+		pidsCurrent:      newDesc("pids", "Number of processes currently in the cgroup and its descendants."),
+		blockDevices:     map[string]string{},
+	}, nil
+}
+
+func (c *cgroupsCollector) Update(ch chan<- prometheus.Metric) error {
+	if _, err := os.Stat(filepath.Join(c.root, "cgroup.controllers")); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			log.Debug().Msgf("%s is not a cgroup v2 hierarchy, skipping", c.root)
+			return ErrNoData
+		}
+		return err
+	}
+	if c.kubernetes {
+		c.pods.update()
+	}
+
+	return filepath.WalkDir(c.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// cgroups come and go while walking the hierarchy.
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(c.root, path)
+		if err != nil {
+			return err
+		}
+		depth := 0
+		if rel != "." {
+			depth = strings.Count(rel, string(filepath.Separator)) + 1
+		}
+		name := "/" + filepath.ToSlash(rel)
+		if depth > c.maxDepth && !(c.kubernetes && depth <= kubernetesContainerDepth && isKubernetesCgroup(name)) {
+			return filepath.SkipDir
+		}
+		if rel == "." {
+			name = "/"
+		}
+		if c.pathFilter.ignored(name) {
+			return nil
+		}
+		c.updateCgroup(ch, path, name)
+		return nil
+	})
+}
+
+// labelValues returns the values of the labels common to all metrics of a
+// cgroup, with the capacity limited so that appending always copies.
+func (c *cgroupsCollector) labelValues(name string) []string {
+	if !c.kubernetes {
+		return []string{name}
+	}
+	values := make([]string, 1, 1+len(kubernetesCgroupLabels))
+	values[0] = name
+	k, ok := parseKubernetesCgroup(name)
+	if !ok {
+		return append(values, "", "", "", "", "")
+	}
+	pod := c.pods.lookup(k.podUID)
+	return append(values, k.podUID, k.qosClass, k.containerID, pod.name, pod.namespace)
+}
+
+func (c *cgroupsCollector) updateCgroup(ch chan<- prometheus.Metric, path, name string) {
+	labels := c.labelValues(name)
+	if cpu, err := readCgroupKeyValues(filepath.Join(path, "cpu.stat")); err == nil {
+		ch <- prometheus.MustNewConstMetric(c.cpuUsage, prometheus.CounterValue, float64(cpu["usage_usec"])/1e6, labels...)
+		ch <- prometheus.MustNewConstMetric(c.cpuUser, prometheus.CounterValue, float64(cpu["user_usec"])/1e6, labels...)
+		ch <- prometheus.MustNewConstMetric(c.cpuSystem, prometheus.CounterValue, float64(cpu["system_usec"])/1e6, labels...)
+		// The bandwidth fields only exist when the cpu controller is enabled.
+		if periods, ok := cpu["nr_periods"]; ok {
+			ch <- prometheus.MustNewConstMetric(c.cpuPeriods, prometheus.CounterValue, float64(periods), labels...)
+			ch <- prometheus.MustNewConstMetric(c.cpuThrottled, prometheus.CounterValue, float64(cpu["nr_throttled"]), labels...)
+			ch <- prometheus.MustNewConstMetric(c.cpuThrottledTime, prometheus.CounterValue, float64(cpu["throttled_usec"])/1e6, labels...)
+		}
+	}
+
+	if current, err := readUintFromFile(filepath.Join(path, "memory.current")); err == nil {
+		ch <- prometheus.MustNewConstMetric(c.memoryCurrent, prometheus.GaugeValue, float64(current), labels...)
+	}
+	if stat, err := readCgroupKeyValues(filepath.Join(path, "memory.stat")); err == nil {
+		for _, field := range cgroupMemoryStatFields {
+			if v, ok := stat[field]; ok {
+				ch <- prometheus.MustNewConstMetric(c.memoryStat, prometheus.GaugeValue, float64(v), append(labels, field)...)
+			}
+		}
+		ch <- prometheus.MustNewConstMetric(c.memoryPgfault, prometheus.CounterValue, float64(stat["pgfault"]), labels...)
+		ch <- prometheus.MustNewConstMetric(c.memoryPgmajfault, prometheus.CounterValue, float64(stat["pgmajfault"]), labels...)
+	}
+	if events, err := readCgroupKeyValues(filepath.Join(path, "memory.events")); err == nil {
+		for event, v := range events {
+			ch <- prometheus.MustNewConstMetric(c.memoryEvents, prometheus.CounterValue, float64(v), append(labels, event)...)
+		}
+	}
+
+	if io, err := readCgroupIOStat(filepath.Join(path, "io.stat")); err == nil {
+		for dev, stat := range io {
+			device := c.blockDeviceName(dev)
+			ch <- prometheus.MustNewConstMetric(c.ioReadBytes, prometheus.CounterValue, float64(stat["rbytes"]), append(labels, device)...)
+			ch <- prometheus.MustNewConstMetric(c.ioWriteBytes, prometheus.CounterValue, float64(stat["wbytes"]), append(labels, device)...)
+			ch <- prometheus.MustNewConstMetric(c.ioReads, prometheus.CounterValue, float64(stat["rios"]), append(labels, device)...)
+			ch <- prometheus.MustNewConstMetric(c.ioWrites, prometheus.CounterValue, float64(stat["wios"]), append(labels, device)...)
+		}
+	}
+
+	if pids, err := readUintFromFile(filepath.Join(path, "pids.current")); err == nil {
+		ch <- prometheus.MustNewConstMetric(c.pidsCurrent, prometheus.GaugeValue, float64(pids), labels...)
+	}
+}
+
+// blockDeviceName resolves a "major:minor" device number of io.stat to the
+// block device name through /sys/dev/block, falling back to the number.
+func (c *cgroupsCollector) blockDeviceName(dev string) string {
+	c.blockDevicesMutex.Lock()
+	defer c.blockDevicesMutex.Unlock()
+
+	if name, ok := c.blockDevices[dev]; ok {
+		return name
+	}
+	name := dev
+	if target, err := os.Readlink(filepath.Join(*sysPath, "dev", "block", dev)); err == nil {
+		name = filepath.Base(target)
+	}
+	c.blockDevices[dev] = name
+	return name
+}
+
+// readCgroupKeyValues reads a flat keyed file like cpu.stat or memory.events.
+func readCgroupKeyValues(path string) (map[string]uint64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	values := map[string]uint64{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		parts := strings.Fields(scanner.Text())
+		if len(parts) != 2 {
+			continue
+		}
+		v, err := strconv.ParseUint(parts[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %s in %s: %w", parts[1], path, err)
+		}
+		values[parts[0]] = v
+	}
+	return values, scanner.Err()
+}
+
+// readCgroupIOStat reads io.stat, a nested keyed file with one line per
+// device like "8:0 rbytes=1 wbytes=2 rios=3 wios=4 dbytes=0 dios=0".
+func readCgroupIOStat(path string) (map[string]map[string]uint64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	stats := map[string]map[string]uint64{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		parts := strings.Fields(scanner.Text())
+		if len(parts) < 2 {
+			continue
+		}
+		stat := map[string]uint64{}
+		for _, kv := range parts[1:] {
+			k, v, ok := strings.Cut(kv, "=")
+			if !ok {
+				continue
+			}
+			n, err := strconv.ParseUint(v, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %s in %s: %w", kv, path, err)
+			}
+			stat[k] = n
+		}
+		stats[parts[0]] = stat
+	}
+	return stats, scanner.Err()
+}
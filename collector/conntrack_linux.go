@@ -0,0 +1,126 @@
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/procfs"
+	"github.com/rs/zerolog/log"
+	"os"
+)
+
+const (
+	conntrackSubsystem = "nf_conntrack"
+)
+
+type conntrackCollector struct {
+	fs            procfs.FS
+	current       *prometheus.Desc
+	limit         *prometheus.Desc
+	found         *prometheus.Desc
+	invalid       *prometheus.Desc
+	ignore        *prometheus.Desc
+	insert        *prometheus.Desc
+	insertFailed  *prometheus.Desc
+	drop          *prometheus.Desc
+	earlyDrop     *prometheus.Desc
+	searchRestart *prometheus.Desc
+}
+
+type conntrackStatistics struct {
+	found         uint64 // Number of searched entries which were successful
+	invalid       uint64 // Number of packets seen which can not be tracked
+	ignore        uint64 // Number of packets seen which are already connected to a conntrack entry
+	insert        uint64 // Number of entries inserted into the list
+	insertFailed  uint64 // Number of entries for which list insertion was attempted but failed (happens if the same entry is already present)
+	drop          uint64 // Number of packets dropped due to conntrack failure. Either new conntrack entry allocation failed, or protocol helper dropped the packet
+	earlyDrop     uint64 // Number of dropped conntrack entries to make room for new ones, if maximum table size was reached
+	searchRestart uint64 // Number of conntrack table lookups which had to be restarted due to hashtable resizes
+}
+
+func init() {
+	registerCollector("conntrack", defaultEnabled, NewConntrackCollector)
+}
+
+// NewConntrackCollector returns a new Collector exposing conntrack stats.
+func NewConntrackCollector() (Collector, error) {
+	fs, err := procfs.NewFS(*procPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open procfs: %w", err)
+	}
+	newDesc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, conntrackSubsystem, name), help, nil, nil)
+	}
+	return &conntrackCollector{
+		fs:            fs,
+		current:       newDesc("entries", "Number of currently allocated flow entries for connection tracking."),
+		limit:         newDesc("entries_limit", "Maximum size of connection tracking table."),
+		found:         newDesc("stat_found_total", "Number of searched entries which were successful."),
+		invalid:       newDesc("stat_invalid_total", "Number of packets seen which can not be tracked."),
+		ignore:        newDesc("stat_ignore_total", "Number of packets seen which are already connected to a conntrack entry."),
+		insert:        newDesc("stat_insert_total", "Number of entries inserted into the list."),
+		insertFailed:  newDesc("stat_insert_failed_total", "Number of entries for which list insertion was attempted but failed."),
+		drop:          newDesc("stat_drop_total", "Number of packets dropped due to conntrack failure."),
+		earlyDrop:     newDesc("stat_early_drop_total", "Number of dropped conntrack entries to make room for new ones, if maximum table size was reached."),
+		searchRestart: newDesc("stat_search_restart_total", "Number of conntrack table lookups which had to be restarted due to hashtable resizes."),
+	}, nil
+}
+
+func (c *conntrackCollector) Update(ch chan<- prometheus.Metric) error {
+	value, err := readUintFromFile(procFilePath("sys/net/netfilter/nf_conntrack_count"))
+	if err != nil {
+		return c.handleErr(err)
+	}
+	ch <- prometheus.MustNewConstMetric(c.current, prometheus.GaugeValue, float64(value))
+
+	value, err = readUintFromFile(procFilePath("sys/net/netfilter/nf_conntrack_max"))
+	if err != nil {
+		return c.handleErr(err)
+	}
+	ch <- prometheus.MustNewConstMetric(c.limit, prometheus.GaugeValue, float64(value))
+
+	stats, err := getConntrackStatistics(c.fs)
+	if err != nil {
+		return c.handleErr(err)
+	}
+	ch <- prometheus.MustNewConstMetric(c.found, prometheus.CounterValue, float64(stats.found))
+	ch <- prometheus.MustNewConstMetric(c.invalid, prometheus.CounterValue, float64(stats.invalid))
+	ch <- prometheus.MustNewConstMetric(c.ignore, prometheus.CounterValue, float64(stats.ignore))
+	ch <- prometheus.MustNewConstMetric(c.insert, prometheus.CounterValue, float64(stats.insert))
+	ch <- prometheus.MustNewConstMetric(c.insertFailed, prometheus.CounterValue, float64(stats.insertFailed))
+	ch <- prometheus.MustNewConstMetric(c.drop, prometheus.CounterValue, float64(stats.drop))
+	ch <- prometheus.MustNewConstMetric(c.earlyDrop, prometheus.CounterValue, float64(stats.earlyDrop))
+	ch <- prometheus.MustNewConstMetric(c.searchRestart, prometheus.CounterValue, float64(stats.searchRestart))
+	return nil
+}
+
+func (c *conntrackCollector) handleErr(err error) error {
+	if errors.Is(err, os.ErrNotExist) {
+		log.Debug().Msg("conntrack probably not loaded")
+		return ErrNoData
+	}
+	return fmt.Errorf("failed to retrieve conntrack stats: %w", err)
+}
+
+// getConntrackStatistics sums the per CPU lines of /proc/net/stat/nf_conntrack.
+func getConntrackStatistics(fs procfs.FS) (*conntrackStatistics, error) {
+	c := conntrackStatistics{}
+
+	connStats, err := fs.ConntrackStat()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, connStat := range connStats {
+		c.found += connStat.Found
+		c.invalid += connStat.Invalid
+		c.ignore += connStat.Ignore
+		c.insert += connStat.Insert
+		c.insertFailed += connStat.InsertFailed
+		c.drop += connStat.Drop
+		c.earlyDrop += connStat.EarlyDrop
+		c.searchRestart += connStat.SearchRestart
+	}
+
+	return &c, nil
+}
@@ -21,4 +21,40 @@ var (
 		nil,
 		nil,
 	)
+	nodeCPUFrequencyHertz = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, cpuCollectorSubsystem, "frequency_hertz"),
+		"Current CPU frequency in hertz.",
+		[]string{"cpu"},
+		nil,
+	)
+	nodeCPUFrequencyMinHertz = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, cpuCollectorSubsystem, "frequency_min_hertz"),
+		"Minimum CPU frequency the governor may select in hertz.",
+		[]string{"cpu"},
+		nil,
+	)
+	nodeCPUFrequencyMaxHertz = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, cpuCollectorSubsystem, "frequency_max_hertz"),
+		"Maximum CPU frequency the governor may select in hertz.",
+		[]string{"cpu"},
+		nil,
+	)
+	nodeCPUScalingGovernor = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, cpuCollectorSubsystem, "scaling_governor"),
+		"Current enabled CPU frequency governor.",
+		[]string{"cpu", "governor"},
+		nil,
+	)
+	nodeCPUCoreThrottles = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, cpuCollectorSubsystem, "core_throttles_total"),
+		"Number of times this CPU core has been throttled.",
+		[]string{"package", "core"},
+		nil,
+	)
+	nodeCPUPackageThrottles = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, cpuCollectorSubsystem, "package_throttles_total"),
+		"Number of times this CPU package has been throttled.",
+		[]string{"package"},
+		nil,
+	)
 )
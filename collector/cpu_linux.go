@@ -1,15 +1,19 @@
 package collector
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/procfs"
+	"github.com/prometheus/procfs/sysfs"
 	"github.com/rs/zerolog/log"
 	"golang.org/x/exp/maps"
 	"golang.org/x/exp/slices"
+	"os"
 	"regexp"
 	"strconv"
+	"strings"
 	"sync"
 )
 
@@ -18,6 +22,8 @@ const jumpBackSeconds = 3.0
 var (
 	flagsInclude         = flag.String("collector.cpu.info.flags-include", "", "Filter the `flags` field in cpuInfo with a value that must be a regular expression")
 	bugsInclude          = flag.String("collector.cpu.info.bugs-include", "", "Filter the `bugs` field in cpuInfo with a value that must be a regular expression")
+	enableCPUFreq        = flag.Bool("collector.cpu.freq", false, "Enables metric node1s_cpu_frequency_hertz and the scaling governor from /sys/devices/system/cpu/cpu*/cpufreq")
+	enableCPUThrottle    = flag.Bool("collector.cpu.thermal-throttle", false, "Enables metrics node1s_cpu_core_throttles_total and node1s_cpu_package_throttles_total")
 	jumpBackDebugMessage = fmt.Sprintf("CPU Idle counter jumped backwards more than %f seconds, possible hotplug event, resetting CPU stats", jumpBackSeconds)
 )
 
@@ -27,6 +33,7 @@ func init() {
 
 type cpuCollector struct {
 	fs            procfs.FS
+	sysfs         sysfs.FS
 	cpu           *prometheus.Desc
 	cpuLogicCount *prometheus.Desc
 
@@ -64,13 +71,14 @@ func NewCPUCollector() (Collector, error) {
 		return nil, fmt.Errorf("failed to open procfs: %w", err)
 	}
 
-	//sysfs, err := sysfs.NewFS(*sysPath)
-	//if err != nil {
-	//	return nil, fmt.Errorf("failed to open sysfs: %w", err)
-	//}
+	sysfs, err := sysfs.NewFS(*sysPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sysfs: %w", err)
+	}
 
 	c := &cpuCollector{
 		fs:            fs,
+		sysfs:         sysfs,
 		cpu:           nodeCPUSecondsDesc,
 		cpuLogicCount: nodeCPULogicCount,
 		cpuStats:      make(map[int64]procfs.CPUStat),
@@ -84,7 +92,129 @@ func NewCPUCollector() (Collector, error) {
 }
 
 func (c *cpuCollector) Update(ch chan<- prometheus.Metric) error {
-	return c.updateStat(ch)
+	if err := c.updateStat(ch); err != nil {
+		return err
+	}
+	if *enableCPUFreq {
+		if err := c.updateCPUFreq(ch); err != nil {
+			return err
+		}
+	}
+	if *enableCPUThrottle {
+		if err := c.updateThermalThrottle(ch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// onlineCPUs returns the CPUs of the last /proc/stat read, which only lists
+// online CPUs, so that the sysfs metrics follow hotplug like the stat ones.
+func (c *cpuCollector) onlineCPUs() map[string]bool {
+	c.cpuStatsMutex.Lock()
+	defer c.cpuStatsMutex.Unlock()
+	online := make(map[string]bool, len(c.cpuStats))
+	for cpuID := range c.cpuStats {
+		online[strconv.Itoa(int(cpuID))] = true
+	}
+	return online
+}
+
+// updateCPUFreq reads /sys/devices/system/cpu/cpu[0-9]*/cpufreq.
+func (c *cpuCollector) updateCPUFreq(ch chan<- prometheus.Metric) error {
+	cpuFreqs, err := c.sysfs.SystemCpufreq()
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			log.Debug().Msg("cpufreq metrics are not available for this system")
+			return nil
+		}
+		return fmt.Errorf("couldn't get cpufreq: %w", err)
+	}
+
+	online := c.onlineCPUs()
+	for _, stats := range cpuFreqs {
+		// CPUs without a cpufreq directory are left empty.
+		if stats.Name == "" || !online[stats.Name] {
+			continue
+		}
+		if cur := firstUint64(stats.ScalingCurrentFrequency, stats.CpuinfoCurrentFrequency); cur != nil {
+			ch <- prometheus.MustNewConstMetric(nodeCPUFrequencyHertz, prometheus.GaugeValue, float64(*cur)*1000.0, stats.Name)
+		}
+		if minFreq := firstUint64(stats.ScalingMinimumFrequency, stats.CpuinfoMinimumFrequency); minFreq != nil {
+			ch <- prometheus.MustNewConstMetric(nodeCPUFrequencyMinHertz, prometheus.GaugeValue, float64(*minFreq)*1000.0, stats.Name)
+		}
+		if maxFreq := firstUint64(stats.ScalingMaximumFrequency, stats.CpuinfoMaximumFrequency); maxFreq != nil {
+			ch <- prometheus.MustNewConstMetric(nodeCPUFrequencyMaxHertz, prometheus.GaugeValue, float64(*maxFreq)*1000.0, stats.Name)
+		}
+		if stats.Governor != "" {
+			availableGovernors := strings.Fields(stats.AvailableGovernors)
+			if !slices.Contains(availableGovernors, stats.Governor) {
+				availableGovernors = append(availableGovernors, stats.Governor)
+			}
+			for _, g := range availableGovernors {
+				state := 0
+				if g == stats.Governor {
+					state = 1
+				}
+				ch <- prometheus.MustNewConstMetric(nodeCPUScalingGovernor, prometheus.GaugeValue, float64(state), stats.Name, g)
+			}
+		}
+	}
+	return nil
+}
+
+// updateThermalThrottle reads /sys/devices/system/cpu/cpu*/thermal_throttle.
+// The counters are shared by the threads of a core and the cores of a
+// package, so each core and package is exported once.
+func (c *cpuCollector) updateThermalThrottle(ch chan<- prometheus.Metric) error {
+	cpus, err := c.sysfs.CPUs()
+	if err != nil {
+		return fmt.Errorf("couldn't get cpus: %w", err)
+	}
+
+	online := c.onlineCPUs()
+	packageThrottles := make(map[string]uint64)
+	coreThrottles := make(map[string]map[string]uint64)
+	for _, cpu := range cpus {
+		if !online[cpu.Number()] {
+			continue
+		}
+		topology, err := cpu.Topology()
+		if err != nil {
+			continue
+		}
+		throttle, err := cpu.ThermalThrottle()
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return fmt.Errorf("couldn't get thermal throttle of cpu %s: %w", cpu.Number(), err)
+		}
+		packageThrottles[topology.PhysicalPackageID] = throttle.PackageThrottleCount
+		if coreThrottles[topology.PhysicalPackageID] == nil {
+			coreThrottles[topology.PhysicalPackageID] = make(map[string]uint64)
+		}
+		coreThrottles[topology.PhysicalPackageID][topology.CoreID] = throttle.CoreThrottleCount
+	}
+
+	for pkg, count := range packageThrottles {
+		ch <- prometheus.MustNewConstMetric(nodeCPUPackageThrottles, prometheus.CounterValue, float64(count), pkg)
+	}
+	for pkg, cores := range coreThrottles {
+		for core, count := range cores {
+			ch <- prometheus.MustNewConstMetric(nodeCPUCoreThrottles, prometheus.CounterValue, float64(count), pkg, core)
+		}
+	}
+	return nil
+}
+
+func firstUint64(values ...*uint64) *uint64 {
+	for _, v := range values {
+		if v != nil {
+			return v
+		}
+	}
+	return nil
 }
 
 func (c *cpuCollector) updateStat(ch chan<- prometheus.Metric) error {
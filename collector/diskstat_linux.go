@@ -1,9 +1,16 @@
 package collector
 
 import (
+	"bufio"
 	"fmt"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/procfs/blockdevice"
+	"github.com/rs/zerolog/log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 )
 
 const (
@@ -13,11 +20,25 @@ const (
 	diskstatsDefaultIgnoredDevices = "^(ram|loop|fd|(h|s|v|xv)d[a-z]|nvme\\d+n\\d+p)\\d+$"
 )
 
+// udev database properties, see the ata_id, scsi_id and path_id udev builtins.
+const (
+	udevIDPath             = "ID_PATH"
+	udevIDModel            = "ID_MODEL"
+	udevIDSerialShort      = "ID_SERIAL_SHORT"
+	udevIDWWN              = "ID_WWN"
+	udevIDWWNWithExtension = "ID_WWN_WITH_EXTENSION"
+	udevSCSIIdentSerial    = "SCSI_IDENT_SERIAL"
+)
+
 type diskstatsCollector struct {
 	deviceFilter         deviceFilter
 	fs                   blockdevice.FS
 	readTimeSecondsDesc  *prometheus.Desc
 	writeTimeSecondsDesc *prometheus.Desc
+
+	// infos caches deviceInfo, it is rebuilt when the set of devices changes.
+	mutex sync.Mutex
+	infos map[blockdevice.Info]map[string]string
 }
 
 func init() {
@@ -48,20 +69,121 @@ func NewDiskstatsCollector() (Collector, error) {
 	return &collector, nil
 }
 
-func (c diskstatsCollector) Update(ch chan<- prometheus.Metric) error {
+func (c *diskstatsCollector) Update(ch chan<- prometheus.Metric) error {
 	diskStats, err := c.fs.ProcDiskstats()
 	if err != nil {
 		return fmt.Errorf("couldn't get diskstats: %w", err)
 	}
 
+	devices := make([]blockdevice.Info, 0, len(diskStats))
+	for _, stats := range diskStats {
+		if !c.deviceFilter.ignored(stats.DeviceName) {
+			devices = append(devices, stats.Info)
+		}
+	}
+	infos := c.deviceInfos(devices)
+
 	for _, stats := range diskStats {
 		dev := stats.DeviceName
 		if c.deviceFilter.ignored(dev) {
 			continue
 		}
 
+		info := infos[stats.Info]
+		ch <- prometheus.MustNewConstMetric(diskInfoDesc, prometheus.GaugeValue, 1,
+			dev,
+			strconv.FormatUint(uint64(stats.MajorNumber), 10),
+			strconv.FormatUint(uint64(stats.MinorNumber), 10),
+			info[udevIDPath],
+			info[udevIDWWN],
+			info[udevIDModel],
+			info[udevIDSerialShort],
+			info["rotational"],
+		)
+
 		ch <- prometheus.MustNewConstMetric(readTimeSecondsDesc, prometheus.CounterValue, float64(stats.ReadTicks)*float64(secondsPerTick), dev)
 		ch <- prometheus.MustNewConstMetric(writeTimeSecondsDesc, prometheus.CounterValue, float64(stats.WriteTicks)*float64(secondsPerTick), dev)
 	}
 	return nil
 }
+
+// deviceInfos returns the deviceInfo of the devices. The udev database and
+// sysfs are only read again when a device is added or removed. The returned
+// map is shared and must not be modified.
+func (c *diskstatsCollector) deviceInfos(devices []blockdevice.Info) map[blockdevice.Info]map[string]string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	changed := len(devices) != len(c.infos)
+	for _, dev := range devices {
+		if _, ok := c.infos[dev]; !ok {
+			changed = true
+			break
+		}
+	}
+	if changed {
+		infos := make(map[blockdevice.Info]map[string]string, len(devices))
+		for _, dev := range devices {
+			infos[dev] = c.deviceInfo(dev)
+		}
+		c.infos = infos
+	}
+	return c.infos
+}
+
+// deviceInfo returns the path, wwn, model, serial and rotational flag of a
+// block device. The udev database is preferred, /sys/block/<dev> fills in
+// what udev does not know, e.g. in containers without /run/udev.
+func (c *diskstatsCollector) deviceInfo(dev blockdevice.Info) map[string]string {
+	info, err := getUdevDeviceProperties(dev.MajorNumber, dev.MinorNumber)
+	if err != nil {
+		log.Debug().Err(err).Msgf("failed to parse udev info for device %s", dev.DeviceName)
+		info = map[string]string{}
+	}
+
+	if info[udevIDWWNWithExtension] != "" {
+		info[udevIDWWN] = info[udevIDWWNWithExtension]
+	}
+	if info[udevIDSerialShort] == "" {
+		info[udevIDSerialShort] = info[udevSCSIIdentSerial]
+	}
+
+	blockPath := sysFilePath(filepath.Join("block", dev.DeviceName))
+	for key, name := range map[string]string{
+		udevIDModel:       "device/model",
+		udevIDSerialShort: "device/serial",
+		udevIDWWN:         "device/wwid",
+		"rotational":      "queue/rotational",
+	} {
+		if info[key] != "" {
+			continue
+		}
+		if value, err := readStringFromFile(filepath.Join(blockPath, name)); err == nil {
+			info[key] = value
+		}
+	}
+	return info
+}
+
+// getUdevDeviceProperties reads the E: properties of the udev database entry
+// of a block device, /run/udev/data/b<major>:<minor>.
+func getUdevDeviceProperties(major, minor uint32) (map[string]string, error) {
+	file, err := os.Open(udevDataFilePath(fmt.Sprintf("b%d:%d", major, minor)))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info := map[string]string{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line, ok := strings.CutPrefix(scanner.Text(), "E:")
+		if !ok {
+			continue
+		}
+		if name, value, ok := strings.Cut(line, "="); ok {
+			info[name] = value
+		}
+	}
+	return info, scanner.Err()
+}
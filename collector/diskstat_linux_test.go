@@ -0,0 +1,81 @@
+package collector
+
+import (
+	"github.com/prometheus/procfs/blockdevice"
+	"testing"
+)
+
+func TestDiskstatsDeviceInfo(t *testing.T) {
+	root := t.TempDir()
+	writeFixture(t, root, map[string]string{
+		"udev/b8:0": "S:disk/by-id/wwn-0x5000c500a1b2c3d4\n" +
+			"E:ID_PATH=pci-0000:00:1f.2-ata-1\n" +
+			"E:ID_WWN=0x5000c500a1b2c3d4\n" +
+			"E:ID_WWN_WITH_EXTENSION=0x5000c500a1b2c3d4ffff\n" +
+			"E:SCSI_IDENT_SERIAL=ZA1B2C3D\n",
+		"sys/block/sda/device/model":     "ST4000NM0035\n",
+		"sys/block/sda/device/serial":    "ignored\n",
+		"sys/block/sda/queue/rotational": "1\n",
+		"sys/block/sdb/device/model":     "Virtual disk\n",
+		"sys/block/sdb/device/wwid":      "naa.6000c29\n",
+		"sys/block/sdb/queue/rotational": "0\n",
+	})
+
+	oldSysPath, oldUdevPath := *sysPath, *udevPath
+	defer func() { *sysPath, *udevPath = oldSysPath, oldUdevPath }()
+	*sysPath = root + "/sys"
+	*udevPath = root + "/udev"
+
+	props, err := getUdevDeviceProperties(8, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if props[udevIDPath] != "pci-0000:00:1f.2-ata-1" || len(props) != 4 {
+		t.Errorf("unexpected udev properties: %v", props)
+	}
+
+	tests := []struct {
+		dev  blockdevice.Info
+		want map[string]string
+	}{
+		{
+			blockdevice.Info{MajorNumber: 8, MinorNumber: 0, DeviceName: "sda"},
+			map[string]string{
+				udevIDPath:        "pci-0000:00:1f.2-ata-1",
+				udevIDWWN:         "0x5000c500a1b2c3d4ffff",
+				udevIDModel:       "ST4000NM0035",
+				udevIDSerialShort: "ZA1B2C3D",
+				"rotational":      "1",
+			},
+		},
+		{
+			// Without an udev database entry everything comes from sysfs.
+			blockdevice.Info{MajorNumber: 8, MinorNumber: 16, DeviceName: "sdb"},
+			map[string]string{
+				udevIDWWN:    "naa.6000c29",
+				udevIDModel:  "Virtual disk",
+				"rotational": "0",
+			},
+		},
+	}
+	c := &diskstatsCollector{}
+	for _, tt := range tests {
+		info := c.deviceInfo(tt.dev)
+		for _, key := range []string{udevIDPath, udevIDWWN, udevIDModel, udevIDSerialShort, "rotational"} {
+			if info[key] != tt.want[key] {
+				t.Errorf("%s: want %s %q, got %q", tt.dev.DeviceName, key, tt.want[key], info[key])
+			}
+		}
+	}
+
+	// The cached infos are only refreshed when the set of devices changes.
+	sda, sdb := tests[0].dev, tests[1].dev
+	c.deviceInfos([]blockdevice.Info{sda})
+	writeFixture(t, root, map[string]string{"sys/block/sda/queue/rotational": "0\n"})
+	if got := c.deviceInfos([]blockdevice.Info{sda})[sda]["rotational"]; got != "1" {
+		t.Errorf("want cached rotational 1, got %q", got)
+	}
+	if got := c.deviceInfos([]blockdevice.Info{sda, sdb})[sda]["rotational"]; got != "0" {
+		t.Errorf("want refreshed rotational 0, got %q", got)
+	}
+}
@@ -38,6 +38,13 @@ var (
 		diskLabelNames,
 		nil,
 	)
+
+	diskInfoDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, diskSubsystem, "info"),
+		"Info of /sys/block/<block_device> and the udev database, value is always 1.",
+		[]string{"device", "major", "minor", "path", "wwn", "model", "serial", "rotational"},
+		nil,
+	)
 )
 
 func newDiskstatsDeviceFilter() (deviceFilter, error) {
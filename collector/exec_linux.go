@@ -0,0 +1,440 @@
+package collector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/rs/zerolog/log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	execSubsystem = "exec"
+)
+
+var (
+	execDirectory      = flag.String("collector.exec.directory", "", "Directory of executable scripts to run, each script prints Prometheus text or JSON metrics to stdout.")
+	execCommands       = stringSliceFlag("collector.exec.command", "Command to run through /bin/sh -c, prints Prometheus text or JSON metrics to stdout. Repeatable.")
+	execInterval       = flag.Duration("collector.exec.interval", 15*time.Second, "Interval between two runs of each script.")
+	execTimeout        = flag.Duration("collector.exec.timeout", 10*time.Second, "Timeout of a single script run, the script process group is killed once it expires.")
+	execMaxConcurrency = flag.Int("collector.exec.max-concurrency", 4, "Maximum number of scripts running at the same time.")
+	execMaxOutputBytes = flag.Int("collector.exec.max-output-bytes", 4<<20, "Maximum size of the stdout of a script run, larger output is discarded and counts as a parse failure.")
+)
+
+// limitedBuffer is a buffer holding at most limit bytes. Writes beyond the
+// limit are discarded, but reported as written so that the script does not
+// block on a full pipe. The buffer is not embedded, as its ReadFrom would
+// bypass the limit in io.Copy.
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if remaining := b.limit - b.buf.Len(); len(p) > remaining {
+		b.truncated = true
+		if remaining > 0 {
+			b.buf.Write(p[:remaining])
+		}
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+func (b *limitedBuffer) Bytes() []byte {
+	return b.buf.Bytes()
+}
+
+func (b *limitedBuffer) String() string {
+	return b.buf.String()
+}
+
+type execScript struct {
+	name string
+	path string
+	args []string
+}
+
+type execResult struct {
+	exitCode  int
+	timedOut  bool
+	parseOK   bool
+	duration  time.Duration
+	timestamp time.Time
+	metrics   []prometheus.Metric
+}
+
+type execCollector struct {
+	exitCode  *prometheus.Desc
+	timeout   *prometheus.Desc
+	success   *prometheus.Desc
+	duration  *prometheus.Desc
+	lastRun   *prometheus.Desc
+	skipped   *prometheus.Desc
+	semaphore chan struct{}
+
+	mutex   sync.Mutex
+	running map[string]bool
+	skips   map[string]uint64
+	results map[string]*execResult
+}
+
+func init() {
+	registerCollector("exec", defaultDisabled, NewExecCollector)
+}
+
+// NewExecCollector returns a new Collector running local scripts in the background
+// and exposing the metrics of their last run.
+func NewExecCollector() (Collector, error) {
+	if *execDirectory == "" && len(*execCommands) == 0 {
+		return nil, errors.New("exec collector needs --collector.exec.directory or --collector.exec.command")
+	}
+	if *execInterval <= 0 || *execTimeout <= 0 {
+		return nil, errors.New("--collector.exec.interval and --collector.exec.timeout must be positive")
+	}
+	if *execMaxConcurrency < 1 {
+		return nil, errors.New("--collector.exec.max-concurrency must be at least 1")
+	}
+	if *execMaxOutputBytes < 1 {
+		return nil, errors.New("--collector.exec.max-output-bytes must be at least 1")
+	}
+
+	labels := []string{"script"}
+	c := &execCollector{
+		exitCode: prometheus.NewDesc(prometheus.BuildFQName(namespace, execSubsystem, "exit_code"),
+			"Exit code of the last script run, -1 if the script was killed or could not be started.", labels, nil,
+		),
+		timeout: prometheus.NewDesc(prometheus.BuildFQName(namespace, execSubsystem, "timeout"),
+			"Whether the last script run hit the timeout.", labels, nil,
+		),
+		success: prometheus.NewDesc(prometheus.BuildFQName(namespace, execSubsystem, "success"),
+			"Whether the last script run exited with 0 and its output was parsed.", labels, nil,
+		),
+		duration: prometheus.NewDesc(prometheus.BuildFQName(namespace, execSubsystem, "duration_seconds"),
+			"Duration of the last script run.", labels, nil,
+		),
+		lastRun: prometheus.NewDesc(prometheus.BuildFQName(namespace, execSubsystem, "last_run_timestamp_seconds"),
+			"Unix timestamp of the end of the last script run.", labels, nil,
+		),
+		skipped: prometheus.NewDesc(prometheus.BuildFQName(namespace, execSubsystem, "skipped_runs_total"),
+			"Number of runs skipped because the previous run was still in progress.", labels, nil,
+		),
+		semaphore: make(chan struct{}, *execMaxConcurrency),
+		running:   map[string]bool{},
+		skips:     map[string]uint64{},
+		results:   map[string]*execResult{},
+	}
+
+	go c.loop()
+	return c, nil
+}
+
+func (c *execCollector) Update(ch chan<- prometheus.Metric) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if len(c.results) == 0 {
+		return ErrNoData
+	}
+
+	for name, r := range c.results {
+		exitCode := float64(r.exitCode)
+		timedOut := 0.0
+		if r.timedOut {
+			timedOut = 1
+		}
+		success := 0.0
+		if r.exitCode == 0 && r.parseOK {
+			success = 1
+		}
+		ch <- prometheus.MustNewConstMetric(c.exitCode, prometheus.GaugeValue, exitCode, name)
+		ch <- prometheus.MustNewConstMetric(c.timeout, prometheus.GaugeValue, timedOut, name)
+		ch <- prometheus.MustNewConstMetric(c.success, prometheus.GaugeValue, success, name)
+		ch <- prometheus.MustNewConstMetric(c.duration, prometheus.GaugeValue, r.duration.Seconds(), name)
+		ch <- prometheus.MustNewConstMetric(c.lastRun, prometheus.GaugeValue, float64(r.timestamp.UnixNano())/1e9, name)
+		ch <- prometheus.MustNewConstMetric(c.skipped, prometheus.CounterValue, float64(c.skips[name]), name)
+		for _, m := range r.metrics {
+			ch <- m
+		}
+	}
+	return nil
+}
+
+func (c *execCollector) loop() {
+	ticker := time.NewTicker(*execInterval)
+	defer ticker.Stop()
+	for {
+		c.schedule()
+		<-ticker.C
+	}
+}
+
+// schedule starts every script that is not already running and drops the
+// results of scripts removed from the directory.
+func (c *execCollector) schedule() {
+	scripts, err := listExecScripts()
+	if err != nil {
+		log.Error().Err(err).Msg("couldn't list exec scripts")
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	known := make(map[string]bool, len(scripts))
+	for _, s := range scripts {
+		known[s.name] = true
+		if c.running[s.name] {
+			c.skips[s.name]++
+			log.Debug().Msgf("exec script %s still running, skipping this interval", s.name)
+			continue
+		}
+		c.running[s.name] = true
+		go c.run(s)
+	}
+	for name := range c.results {
+		if !known[name] && !c.running[name] {
+			delete(c.results, name)
+			delete(c.skips, name)
+		}
+	}
+}
+
+func (c *execCollector) run(s execScript) {
+	c.semaphore <- struct{}{}
+	r := runExecScript(s)
+	<-c.semaphore
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.running[s.name] = false
+	c.results[s.name] = r
+}
+
+func listExecScripts() ([]execScript, error) {
+	scripts := make([]execScript, 0, len(*execCommands))
+	for _, command := range *execCommands {
+		scripts = append(scripts, execScript{name: command, path: "/bin/sh", args: []string{"-c", command}})
+	}
+	if *execDirectory == "" {
+		return scripts, nil
+	}
+
+	entries, err := os.ReadDir(*execDirectory)
+	if err != nil {
+		return scripts, err
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() || info.Mode().Perm()&0111 == 0 {
+			continue
+		}
+		scripts = append(scripts, execScript{name: entry.Name(), path: filepath.Join(*execDirectory, entry.Name())})
+	}
+	return scripts, nil
+}
+
+func runExecScript(s execScript) *execResult {
+	ctx, cancel := context.WithTimeout(context.Background(), *execTimeout)
+	defer cancel()
+
+	stdout := limitedBuffer{limit: *execMaxOutputBytes}
+	stderr := limitedBuffer{limit: *execMaxOutputBytes}
+	cmd := exec.CommandContext(ctx, s.path, s.args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// Kill the whole process group, so that children of a shell script do not
+	// outlive the timeout and keep stdout open.
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	cmd.WaitDelay = time.Second
+
+	start := time.Now()
+	err := cmd.Run()
+	r := &execResult{
+		duration:  time.Since(start),
+		timestamp: time.Now(),
+		timedOut:  errors.Is(ctx.Err(), context.DeadlineExceeded),
+	}
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		r.exitCode = 0
+	case errors.As(err, &exitErr):
+		r.exitCode = exitErr.ExitCode()
+	default:
+		r.exitCode = -1
+	}
+	if err != nil {
+		log.Warn().Err(err).Str("stderr", strings.TrimSpace(stderr.String())).Msgf("exec script %s failed", s.name)
+	}
+	if r.timedOut {
+		return r
+	}
+	if stdout.truncated {
+		log.Warn().Msgf("output of exec script %s exceeds %d bytes, discarding it", s.name, stdout.limit)
+		return r
+	}
+
+	families, err := parseExecOutput(stdout.Bytes())
+	if err != nil {
+		log.Warn().Err(err).Msgf("couldn't parse output of exec script %s", s.name)
+		return r
+	}
+	r.parseOK = true
+	r.metrics = convertMetricFamilies(s.name, families)
+	return r
+}
+
+// execJSONMetric is a single sample of the JSON output format. Scripts can
+// either print a list of these or an object mapping metric names to values.
+type execJSONMetric struct {
+	Name   string            `json:"name"`
+	Help   string            `json:"help"`
+	Type   string            `json:"type"`
+	Labels map[string]string `json:"labels"`
+	Value  float64           `json:"value"`
+}
+
+func parseExecOutput(data []byte) (map[string]*dto.MetricFamily, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return map[string]*dto.MetricFamily{}, nil
+	}
+	switch trimmed[0] {
+	case '[':
+		var samples []execJSONMetric
+		if err := json.Unmarshal(trimmed, &samples); err != nil {
+			return nil, err
+		}
+		return jsonToMetricFamilies(samples)
+	case '{':
+		var values map[string]float64
+		if err := json.Unmarshal(trimmed, &values); err != nil {
+			return nil, err
+		}
+		samples := make([]execJSONMetric, 0, len(values))
+		for name, value := range values {
+			samples = append(samples, execJSONMetric{Name: name, Value: value})
+		}
+		return jsonToMetricFamilies(samples)
+	}
+	var parser expfmt.TextParser
+	return parser.TextToMetricFamilies(bytes.NewReader(append(trimmed, '\n')))
+}
+
+func jsonToMetricFamilies(samples []execJSONMetric) (map[string]*dto.MetricFamily, error) {
+	families := map[string]*dto.MetricFamily{}
+	for _, s := range samples {
+		if s.Name == "" {
+			return nil, errors.New("json metric without name")
+		}
+		mf, ok := families[s.Name]
+		if !ok {
+			metricType := dto.MetricType_UNTYPED
+			switch strings.ToLower(s.Type) {
+			case "counter":
+				metricType = dto.MetricType_COUNTER
+			case "gauge":
+				metricType = dto.MetricType_GAUGE
+			case "", "untyped":
+			default:
+				return nil, fmt.Errorf("unsupported type %q of json metric %s", s.Type, s.Name)
+			}
+			name, help := s.Name, s.Help
+			mf = &dto.MetricFamily{Name: &name, Help: &help, Type: &metricType}
+			families[s.Name] = mf
+		}
+
+		value := s.Value
+		m := &dto.Metric{}
+		for k, v := range s.Labels {
+			k, v := k, v
+			m.Label = append(m.Label, &dto.LabelPair{Name: &k, Value: &v})
+		}
+		sort.Slice(m.Label, func(i, j int) bool { return m.Label[i].GetName() < m.Label[j].GetName() })
+		switch mf.GetType() {
+		case dto.MetricType_COUNTER:
+			m.Counter = &dto.Counter{Value: &value}
+		case dto.MetricType_GAUGE:
+			m.Gauge = &dto.Gauge{Value: &value}
+		default:
+			m.Untyped = &dto.Untyped{Value: &value}
+		}
+		mf.Metric = append(mf.Metric, m)
+	}
+	return families, nil
+}
+
+// convertMetricFamilies converts the metrics of a script run, adding the script
+// label so that scripts exporting the same metric names do not collide.
+// Metrics in the namespace of the exporter are dropped.
+func convertMetricFamilies(script string, families map[string]*dto.MetricFamily) []prometheus.Metric {
+	var metrics []prometheus.Metric
+	for _, mf := range families {
+		if strings.HasPrefix(mf.GetName(), namespace+"_") {
+			log.Warn().Msgf("exec script %s exports %s in the namespace of the exporter, dropping it", script, mf.GetName())
+			continue
+		}
+		help := mf.GetHelp()
+		if help == "" {
+			help = fmt.Sprintf("Metric read from exec script %s.", mf.GetName())
+		}
+		for _, m := range mf.GetMetric() {
+			names := make([]string, 0, len(m.GetLabel()))
+			values := make([]string, 0, len(m.GetLabel()))
+			for _, l := range m.GetLabel() {
+				names = append(names, l.GetName())
+				values = append(values, l.GetValue())
+			}
+			desc := prometheus.NewDesc(mf.GetName(), help, names, prometheus.Labels{"script": script})
+
+			var (
+				metric prometheus.Metric
+				err    error
+			)
+			switch mf.GetType() {
+			case dto.MetricType_COUNTER:
+				metric, err = prometheus.NewConstMetric(desc, prometheus.CounterValue, m.GetCounter().GetValue(), values...)
+			case dto.MetricType_GAUGE:
+				metric, err = prometheus.NewConstMetric(desc, prometheus.GaugeValue, m.GetGauge().GetValue(), values...)
+			case dto.MetricType_SUMMARY:
+				quantiles := map[float64]float64{}
+				for _, q := range m.GetSummary().GetQuantile() {
+					quantiles[q.GetQuantile()] = q.GetValue()
+				}
+				metric, err = prometheus.NewConstSummary(desc, m.GetSummary().GetSampleCount(), m.GetSummary().GetSampleSum(), quantiles, values...)
+			case dto.MetricType_HISTOGRAM:
+				buckets := map[float64]uint64{}
+				for _, b := range m.GetHistogram().GetBucket() {
+					buckets[b.GetUpperBound()] = b.GetCumulativeCount()
+				}
+				metric, err = prometheus.NewConstHistogram(desc, m.GetHistogram().GetSampleCount(), m.GetHistogram().GetSampleSum(), buckets, values...)
+			default:
+				metric, err = prometheus.NewConstMetric(desc, prometheus.UntypedValue, m.GetUntyped().GetValue(), values...)
+			}
+			if err != nil {
+				log.Warn().Err(err).Msgf("couldn't convert metric %s of exec script %s", mf.GetName(), script)
+				continue
+			}
+			metrics = append(metrics, metric)
+		}
+	}
+	return metrics
+}
@@ -0,0 +1,45 @@
+package collector
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestParseExecOutput(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		metrics int
+	}{
+		{"empty", "", 0},
+		{"text", "# TYPE vendor_up gauge\nvendor_up{raid=\"a\"} 1\nvendor_up{raid=\"b\"} 0\n", 2},
+		{"json list", `[{"name":"vendor_temp","type":"gauge","labels":{"slot":"1"},"value":41.5},{"name":"vendor_errors","type":"counter","value":3}]`, 2},
+		{"json object", `{"vendor_a": 1, "vendor_b": 2}`, 2},
+		{"exporter namespace", "node1s_load1 1\nvendor_up 1\n", 1},
+	}
+	for _, tt := range tests {
+		families, err := parseExecOutput([]byte(tt.output))
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if got := len(convertMetricFamilies("test", families)); got != tt.metrics {
+			t.Errorf("%s: want %d metrics, got %d", tt.name, tt.metrics, got)
+		}
+	}
+
+	if _, err := parseExecOutput([]byte(`[{"value": 1}]`)); err == nil {
+		t.Error("want error for json metric without name")
+	}
+}
+
+func TestLimitedBuffer(t *testing.T) {
+	b := limitedBuffer{limit: 4}
+	// io.Copy is what os/exec uses to fill a non file stdout.
+	if n, err := io.Copy(&b, strings.NewReader("abcdef")); n != 6 || err != nil {
+		t.Fatalf("want 6 <nil>, got %d %v", n, err)
+	}
+	if b.String() != "abcd" || !b.truncated {
+		t.Errorf("want truncated abcd, got %q %v", b.String(), b.truncated)
+	}
+}
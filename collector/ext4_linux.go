@@ -0,0 +1,98 @@
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog/log"
+	"os"
+	"path/filepath"
+)
+
+const (
+	ext4Subsystem = "ext4"
+)
+
+type ext4Collector struct {
+	errors         *prometheus.Desc
+	warnings       *prometheus.Desc
+	messages       *prometheus.Desc
+	firstErrorTime *prometheus.Desc
+	lastErrorTime  *prometheus.Desc
+	lifetimeWrites *prometheus.Desc
+}
+
+func init() {
+	registerCollector(ext4Subsystem, defaultEnabled, NewExt4Collector)
+}
+
+// NewExt4Collector returns a new Collector exposing the error counters of the
+// mounted ext4 filesystems of /sys/fs/ext4/<dev>.
+// Docs from https://www.kernel.org/doc/Documentation/ABI/testing/sysfs-fs-ext4
+func NewExt4Collector() (Collector, error) {
+	labels := []string{"device"}
+	return &ext4Collector{
+		errors: prometheus.NewDesc(prometheus.BuildFQName(namespace, ext4Subsystem, "errors_total"),
+			"Number of errors recorded in the superblock of the filesystem.", labels, nil,
+		),
+		warnings: prometheus.NewDesc(prometheus.BuildFQName(namespace, ext4Subsystem, "warnings_total"),
+			"Number of warnings logged by the filesystem.", labels, nil,
+		),
+		messages: prometheus.NewDesc(prometheus.BuildFQName(namespace, ext4Subsystem, "messages_total"),
+			"Number of messages logged by the filesystem.", labels, nil,
+		),
+		firstErrorTime: prometheus.NewDesc(prometheus.BuildFQName(namespace, ext4Subsystem, "first_error_time_seconds"),
+			"Unix time of the first error of the filesystem, 0 if there was none.", labels, nil,
+		),
+		lastErrorTime: prometheus.NewDesc(prometheus.BuildFQName(namespace, ext4Subsystem, "last_error_time_seconds"),
+			"Unix time of the last error of the filesystem, 0 if there was none.", labels, nil,
+		),
+		lifetimeWrites: prometheus.NewDesc(prometheus.BuildFQName(namespace, ext4Subsystem, "lifetime_written_bytes_total"),
+			"Number of bytes written to the filesystem since it was created.", labels, nil,
+		),
+	}, nil
+}
+
+func (c *ext4Collector) Update(ch chan<- prometheus.Metric) error {
+	ext4Path := sysFilePath(filepath.Join("fs", "ext4"))
+	entries, err := os.ReadDir(ext4Path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			log.Debug().Msg("ext4 statistics not found, skipping")
+			return ErrNoData
+		}
+		return fmt.Errorf("couldn't list ext4 filesystems: %w", err)
+	}
+
+	for _, entry := range entries {
+		// features describes the driver, not a filesystem.
+		if !entry.IsDir() || entry.Name() == "features" {
+			continue
+		}
+		device := entry.Name()
+		devPath := filepath.Join(ext4Path, device)
+
+		// The counters are missing on older kernels, and the directory goes
+		// away when the filesystem is unmounted during the scrape.
+		for _, m := range []struct {
+			file      string
+			desc      *prometheus.Desc
+			valueType prometheus.ValueType
+			scale     float64
+		}{
+			{"errors_count", c.errors, prometheus.CounterValue, 1},
+			{"warning_count", c.warnings, prometheus.CounterValue, 1},
+			{"msg_count", c.messages, prometheus.CounterValue, 1},
+			{"first_error_time", c.firstErrorTime, prometheus.GaugeValue, 1},
+			{"last_error_time", c.lastErrorTime, prometheus.GaugeValue, 1},
+			{"lifetime_write_kbytes", c.lifetimeWrites, prometheus.CounterValue, 1024},
+		} {
+			value, err := readUintFromFile(filepath.Join(devPath, m.file))
+			if err != nil {
+				continue
+			}
+			ch <- prometheus.MustNewConstMetric(m.desc, m.valueType, float64(value)*m.scale, device)
+		}
+	}
+	return nil
+}
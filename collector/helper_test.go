@@ -0,0 +1,45 @@
+package collector
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testCollector adapts a Collector to a prometheus.Collector for testutil,
+// failing the test when Update returns an error.
+type testCollector struct {
+	Collector
+	t *testing.T
+}
+
+func (c testCollector) Describe(chan<- *prometheus.Desc) {}
+
+func (c testCollector) Collect(ch chan<- prometheus.Metric) {
+	if err := c.Update(ch); err != nil {
+		c.t.Errorf("Update: %v", err)
+	}
+}
+
+// writeFixture creates the files and symlinks of a fixture tree below root.
+// Values starting with "->" are symlink targets.
+func writeFixture(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		var err error
+		if target, ok := strings.CutPrefix(content, "->"); ok {
+			err = os.Symlink(target, path)
+		} else {
+			err = os.WriteFile(path, []byte(content), 0o644)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
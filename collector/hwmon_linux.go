@@ -0,0 +1,184 @@
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog/log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	hwmonSubsystem = "hwmon"
+)
+
+var (
+	hwmonSensorPattern = regexp.MustCompile(`^(temp|fan|in|curr|power)(\d+)_([a-z_]+)$`)
+	hwmonInvalidChars  = regexp.MustCompile(`[^a-z0-9:_]`)
+
+	// hwmonUnits maps a sensor type to the unit suffix of its metrics and the
+	// factor converting the sysfs value to that unit.
+	// Docs from https://www.kernel.org/doc/Documentation/hwmon/sysfs-interface
+	hwmonUnits = map[string]struct {
+		unit  string
+		scale float64
+	}{
+		"temp":  {"celsius", 0.001},
+		"fan":   {"rpm", 1},
+		"in":    {"volts", 0.001},
+		"curr":  {"amps", 0.001},
+		"power": {"watts", 0.000001},
+	}
+)
+
+type hwmonCollector struct {
+	chipName    *prometheus.Desc
+	sensorLabel *prometheus.Desc
+
+	// sensors are the descs of the sensor metrics by name, created when a
+	// sensor is first seen.
+	mutex   sync.Mutex
+	sensors map[string]*prometheus.Desc
+}
+
+func init() {
+	registerCollector(hwmonSubsystem, defaultEnabled, NewHwMonCollector)
+}
+
+// NewHwMonCollector returns a new Collector exposing the hardware monitoring
+// sensors of /sys/class/hwmon.
+func NewHwMonCollector() (Collector, error) {
+	return &hwmonCollector{
+		chipName: prometheus.NewDesc(prometheus.BuildFQName(namespace, hwmonSubsystem, "chip_names"),
+			"Annotation metric for human-readable chip names.", []string{"chip", "hwmon", "chip_name"}, nil,
+		),
+		sensorLabel: prometheus.NewDesc(prometheus.BuildFQName(namespace, hwmonSubsystem, "sensor_label"),
+			"Label for given chip and sensor.", []string{"chip", "hwmon", "sensor", "label"}, nil,
+		),
+		sensors: map[string]*prometheus.Desc{},
+	}, nil
+}
+
+func (c *hwmonCollector) Update(ch chan<- prometheus.Metric) error {
+	hwmonPath := filepath.Join(*sysPath, "class", "hwmon")
+	devices, err := os.ReadDir(hwmonPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			log.Debug().Msg("hwmon collector metrics are not available for this system")
+			return ErrNoData
+		}
+		return err
+	}
+
+	for _, device := range devices {
+		dir := filepath.Join(hwmonPath, device.Name())
+		if err := c.updateHwmon(ch, dir); err != nil {
+			return fmt.Errorf("couldn't get hwmon stats of %s: %w", dir, err)
+		}
+	}
+	return nil
+}
+
+// updateHwmon exports the sensors of a hwmon device. The chip name alone isn't
+// unique, a device can register several hwmon devices, so the hwmon device is
+// exported as well.
+func (c *hwmonCollector) updateHwmon(ch chan<- prometheus.Metric, dir string) error {
+	chip, hwmon := hwmonChipName(dir), filepath.Base(dir)
+
+	// Older drivers put the sensor files below the device directory.
+	sensorDir := dir
+	if _, err := os.Stat(filepath.Join(dir, "name")); err != nil {
+		if _, err := os.Stat(filepath.Join(dir, "device", "name")); err == nil {
+			sensorDir = filepath.Join(dir, "device")
+		}
+	}
+
+	if name, err := readStringFromFile(filepath.Join(sensorDir, "name")); err == nil {
+		ch <- prometheus.MustNewConstMetric(c.chipName, prometheus.GaugeValue, 1, chip, hwmon, name)
+	}
+
+	files, err := os.ReadDir(sensorDir)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		m := hwmonSensorPattern.FindStringSubmatch(file.Name())
+		if m == nil {
+			continue
+		}
+		sensorType, sensor, attribute := m[1], m[1]+m[2], m[3]
+		path := filepath.Join(sensorDir, file.Name())
+
+		if attribute == "label" {
+			label, err := readStringFromFile(path)
+			if err != nil {
+				continue
+			}
+			ch <- prometheus.MustNewConstMetric(c.sensorLabel, prometheus.GaugeValue, 1, chip, hwmon, sensor, label)
+			continue
+		}
+
+		raw, err := readStringFromFile(path)
+		if err != nil {
+			// Some attributes are write only or return EIO when the sensor is
+			// not connected.
+			continue
+		}
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			continue
+		}
+
+		var name string
+		switch {
+		case strings.HasSuffix(attribute, "alarm"):
+			name = sensorType + "_" + attribute
+		case attribute == "input":
+			name = sensorType + "_" + hwmonUnits[sensorType].unit
+			value *= hwmonUnits[sensorType].scale
+		case attribute == "max" || attribute == "min" || attribute == "crit" || attribute == "lcrit" || attribute == "average" || attribute == "highest" || attribute == "lowest":
+			name = sensorType + "_" + attribute + "_" + hwmonUnits[sensorType].unit
+			value *= hwmonUnits[sensorType].scale
+		default:
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(c.sensorDesc(name, sensorType, attribute), prometheus.GaugeValue, value, chip, hwmon, sensor)
+	}
+	return nil
+}
+
+// sensorDesc returns the desc of the sensor metric name, creating it on first
+// use.
+func (c *hwmonCollector) sensorDesc(name, sensorType, attribute string) *prometheus.Desc {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	desc, ok := c.sensors[name]
+	if !ok {
+		desc = prometheus.NewDesc(prometheus.BuildFQName(namespace, hwmonSubsystem, name),
+			fmt.Sprintf("Hardware monitor %s value %s.", sensorType, attribute), []string{"chip", "hwmon", "sensor"}, nil,
+		)
+		c.sensors[name] = desc
+	}
+	return desc
+}
+
+// hwmonChipName returns a stable name for a hwmon device built from the
+// device it belongs to, e.g. platform_coretemp_0, since the hwmonN numbering
+// depends on the probe order.
+func hwmonChipName(dir string) string {
+	device, err := filepath.EvalSymlinks(filepath.Join(dir, "device"))
+	if err != nil {
+		return filepath.Base(dir)
+	}
+	name := filepath.Base(device)
+	if subsystem, err := filepath.EvalSymlinks(filepath.Join(device, "subsystem")); err == nil {
+		name = filepath.Base(subsystem) + "_" + name
+	}
+	return hwmonInvalidChars.ReplaceAllString(strings.ToLower(name), "_")
+}
@@ -0,0 +1,67 @@
+package collector
+
+import (
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHwmon(t *testing.T) {
+	root := t.TempDir()
+	writeFixture(t, root, map[string]string{
+		"class/hwmon/hwmon1":                                        "->../../devices/platform/coretemp.0/hwmon/hwmon1",
+		"devices/platform/coretemp.0/subsystem":                     "->../../../bus/platform",
+		"bus/platform/.keep":                                        "",
+		"devices/platform/coretemp.0/hwmon/hwmon1/device":           "->../../../coretemp.0",
+		"devices/platform/coretemp.0/hwmon/hwmon1/name":             "coretemp\n",
+		"devices/platform/coretemp.0/hwmon/hwmon1/temp1_input":      "45000\n",
+		"devices/platform/coretemp.0/hwmon/hwmon1/temp1_crit":       "100000\n",
+		"devices/platform/coretemp.0/hwmon/hwmon1/temp1_label":      "Package id 0\n",
+		"devices/platform/coretemp.0/hwmon/hwmon1/temp1_crit_alarm": "1\n",
+		"devices/platform/coretemp.0/hwmon/hwmon1/power1_average":   "15500000\n",
+		"devices/platform/coretemp.0/hwmon/hwmon1/uevent":           "",
+		// A second hwmon device of the same device has the same chip name.
+		"class/hwmon/hwmon2":                                   "->../../devices/platform/coretemp.0/hwmon/hwmon2",
+		"devices/platform/coretemp.0/hwmon/hwmon2/device":      "->../../../coretemp.0",
+		"devices/platform/coretemp.0/hwmon/hwmon2/name":        "coretemp\n",
+		"devices/platform/coretemp.0/hwmon/hwmon2/temp1_input": "50000\n",
+	})
+
+	oldSysPath := *sysPath
+	defer func() { *sysPath = oldSysPath }()
+	*sysPath = root
+
+	if chip := hwmonChipName(filepath.Join(root, "class/hwmon/hwmon1")); chip != "platform_coretemp_0" {
+		t.Errorf("want chip platform_coretemp_0, got %s", chip)
+	}
+
+	c, err := NewHwMonCollector()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP node1s_hwmon_chip_names Annotation metric for human-readable chip names.
+# TYPE node1s_hwmon_chip_names gauge
+node1s_hwmon_chip_names{chip="platform_coretemp_0",chip_name="coretemp",hwmon="hwmon1"} 1
+node1s_hwmon_chip_names{chip="platform_coretemp_0",chip_name="coretemp",hwmon="hwmon2"} 1
+# HELP node1s_hwmon_power_average_watts Hardware monitor power value average.
+# TYPE node1s_hwmon_power_average_watts gauge
+node1s_hwmon_power_average_watts{chip="platform_coretemp_0",hwmon="hwmon1",sensor="power1"} 15.5
+# HELP node1s_hwmon_sensor_label Label for given chip and sensor.
+# TYPE node1s_hwmon_sensor_label gauge
+node1s_hwmon_sensor_label{chip="platform_coretemp_0",hwmon="hwmon1",label="Package id 0",sensor="temp1"} 1
+# HELP node1s_hwmon_temp_celsius Hardware monitor temp value input.
+# TYPE node1s_hwmon_temp_celsius gauge
+node1s_hwmon_temp_celsius{chip="platform_coretemp_0",hwmon="hwmon1",sensor="temp1"} 45
+node1s_hwmon_temp_celsius{chip="platform_coretemp_0",hwmon="hwmon2",sensor="temp1"} 50
+# HELP node1s_hwmon_temp_crit_alarm Hardware monitor temp value crit_alarm.
+# TYPE node1s_hwmon_temp_crit_alarm gauge
+node1s_hwmon_temp_crit_alarm{chip="platform_coretemp_0",hwmon="hwmon1",sensor="temp1"} 1
+# HELP node1s_hwmon_temp_crit_celsius Hardware monitor temp value crit.
+# TYPE node1s_hwmon_temp_crit_celsius gauge
+node1s_hwmon_temp_crit_celsius{chip="platform_coretemp_0",hwmon="hwmon1",sensor="temp1"} 100
+`
+	if err := testutil.CollectAndCompare(testCollector{c, t}, strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+}
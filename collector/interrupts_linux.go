@@ -0,0 +1,129 @@
+package collector
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+var (
+	interruptsAggregateCPUs = flag.Bool("collector.interrupts.aggregate-cpus", false, "Sum the interrupts of all CPUs instead of exporting one series per CPU.")
+)
+
+type interruptsCollector struct {
+	desc          typedDesc
+	aggregateDesc typedDesc
+}
+
+type interrupt struct {
+	info    string
+	devices string
+	values  []string
+}
+
+func init() {
+	registerCollector("interrupts", defaultDisabled, NewInterruptsCollector)
+}
+
+// NewInterruptsCollector returns a new Collector exposing interrupts stats.
+func NewInterruptsCollector() (Collector, error) {
+	return &interruptsCollector{
+		desc: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "interrupts", "total"),
+			"Interrupt details.",
+			[]string{"cpu", "irq", "type", "devices"}, nil,
+		), prometheus.CounterValue},
+		aggregateDesc: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "interrupts", "total"),
+			"Interrupt details summed over all CPUs.",
+			[]string{"irq", "type", "devices"}, nil,
+		), prometheus.CounterValue},
+	}, nil
+}
+
+func (c *interruptsCollector) Update(ch chan<- prometheus.Metric) error {
+	cpus, interrupts, err := getInterrupts()
+	if err != nil {
+		return fmt.Errorf("couldn't get interrupts: %w", err)
+	}
+	for name, interrupt := range interrupts {
+		var sum float64
+		for i, value := range interrupt.values {
+			fv, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return fmt.Errorf("invalid value %s in interrupts: %w", value, err)
+			}
+			if *interruptsAggregateCPUs {
+				sum += fv
+				continue
+			}
+			ch <- c.desc.mustNewConstMetric(fv, cpus[i], name, interrupt.info, interrupt.devices)
+		}
+		if *interruptsAggregateCPUs {
+			ch <- c.aggregateDesc.mustNewConstMetric(sum, name, interrupt.info, interrupt.devices)
+		}
+	}
+	return nil
+}
+
+func getInterrupts() ([]string, map[string]interrupt, error) {
+	file, err := os.Open(procFilePath("interrupts"))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	return parseInterrupts(file)
+}
+
+// parseInterrupts returns the CPU numbers of the header line and the
+// interrupts keyed by IRQ. Offline CPUs are missing from the header, so the
+// CPU numbers are taken from it instead of counting columns.
+func parseInterrupts(r io.Reader) ([]string, map[string]interrupt, error) {
+	var (
+		interrupts = map[string]interrupt{}
+		scanner    = bufio.NewScanner(r)
+	)
+
+	if !scanner.Scan() {
+		return nil, nil, errors.New("interrupts empty")
+	}
+	cpus := strings.Fields(scanner.Text())
+	for i, cpu := range cpus {
+		cpus[i] = strings.TrimPrefix(cpu, "CPU")
+	}
+	cpuNum := len(cpus)
+
+	for scanner.Scan() {
+		parts := strings.Fields(scanner.Text())
+		if len(parts) < 2 {
+			continue
+		}
+		intName := strings.TrimSuffix(parts[0], ":")
+		// Some architecture specific lines like ERR and MIS only have a
+		// single total value.
+		if len(parts) < cpuNum+1 {
+			continue
+		}
+		intr := interrupt{
+			values: parts[1 : cpuNum+1],
+		}
+
+		if _, err := strconv.Atoi(intName); err == nil && len(parts) > cpuNum+1 {
+			// numeral interrupt
+			intr.info = parts[cpuNum+1]
+			intr.devices = strings.Join(parts[cpuNum+2:], " ")
+		} else {
+			intr.info = strings.Join(parts[cpuNum+1:], " ")
+		}
+		interrupts[intName] = intr
+	}
+
+	return cpus, interrupts, scanner.Err()
+}
@@ -0,0 +1,32 @@
+package collector
+
+import (
+	"strings"
+	"testing"
+)
+
+const interruptsFixture = `           CPU0       CPU2
+  0:         30          0   IO-APIC   2-edge      timer
+ 24:          1          5  PCI-MSI 1572864-edge      eth0-rx-0
+NMI:          0          3   Non-maskable interrupts
+ERR:          0
+`
+
+func TestParseInterrupts(t *testing.T) {
+	cpus, interrupts, err := parseInterrupts(strings.NewReader(interruptsFixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"0", "2"}; strings.Join(cpus, ",") != strings.Join(want, ",") {
+		t.Errorf("want cpus %v, got %v", want, cpus)
+	}
+	if len(interrupts) != 3 {
+		t.Fatalf("want 3 interrupts, got %d", len(interrupts))
+	}
+	if got := interrupts["24"]; got.info != "PCI-MSI" || got.devices != "1572864-edge eth0-rx-0" || got.values[1] != "5" {
+		t.Errorf("unexpected interrupt 24: %+v", got)
+	}
+	if got := interrupts["NMI"]; got.info != "Non-maskable interrupts" || got.devices != "" {
+		t.Errorf("unexpected interrupt NMI: %+v", got)
+	}
+}
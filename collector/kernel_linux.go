@@ -0,0 +1,142 @@
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog/log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	kernelSubsystem = "kernel"
+)
+
+type kernelCollector struct {
+	fileFDAllocated typedDesc
+	fileFDMaximum   typedDesc
+	inodes          typedDesc
+	inodesFree      typedDesc
+	entropyAvail    typedDesc
+	entropyPoolSize typedDesc
+	pids            typedDesc
+	pidMax          typedDesc
+	pidUsage        typedDesc
+}
+
+func init() {
+	registerCollector(kernelSubsystem, defaultEnabled, NewKernelCollector)
+}
+
+// NewKernelCollector returns a new Collector exposing the usage of global
+// kernel tables: file handles, inodes, entropy and pids.
+// Docs from https://www.kernel.org/doc/Documentation/sysctl/fs.txt
+func NewKernelCollector() (Collector, error) {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, kernelSubsystem, name), help, nil, nil)
+	}
+	return &kernelCollector{
+		fileFDAllocated: typedDesc{desc("filefd_allocated", "Number of allocated file handles."), prometheus.GaugeValue},
+		fileFDMaximum:   typedDesc{desc("filefd_maximum", "Maximum number of file handles."), prometheus.GaugeValue},
+		inodes:          typedDesc{desc("inodes_allocated", "Number of allocated inodes."), prometheus.GaugeValue},
+		inodesFree:      typedDesc{desc("inodes_free", "Number of allocated inodes that are free."), prometheus.GaugeValue},
+		entropyAvail:    typedDesc{desc("entropy_available_bits", "Bits of available entropy."), prometheus.GaugeValue},
+		entropyPoolSize: typedDesc{desc("entropy_pool_size_bits", "Bits of entropy pool."), prometheus.GaugeValue},
+		pids:            typedDesc{desc("pids", "Number of processes and threads, each using a pid."), prometheus.GaugeValue},
+		pidMax:          typedDesc{desc("pid_max", "Value above which pids wrap around."), prometheus.GaugeValue},
+		pidUsage:        typedDesc{desc("pid_usage_ratio", "Ratio of pids in use to pid_max."), prometheus.GaugeValue},
+	}, nil
+}
+
+func (c *kernelCollector) Update(ch chan<- prometheus.Metric) error {
+	// file-nr holds the allocated, the unused (always 0 since Linux 2.6) and
+	// the maximum file handles.
+	if values, err := readKernelValues("sys/fs/file-nr", 3); err == nil {
+		ch <- c.fileFDAllocated.mustNewConstMetric(values[0])
+		ch <- c.fileFDMaximum.mustNewConstMetric(values[2])
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	// inode-nr holds the allocated and the free inodes.
+	if values, err := readKernelValues("sys/fs/inode-nr", 2); err == nil {
+		ch <- c.inodes.mustNewConstMetric(values[0])
+		ch <- c.inodesFree.mustNewConstMetric(values[1])
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	for _, e := range []struct {
+		file string
+		desc typedDesc
+	}{
+		{"sys/kernel/random/entropy_avail", c.entropyAvail},
+		{"sys/kernel/random/poolsize", c.entropyPoolSize},
+	} {
+		values, err := readKernelValues(e.file, 1)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				log.Debug().Msgf("%s does not exist, skipping", e.file)
+				continue
+			}
+			return err
+		}
+		ch <- e.desc.mustNewConstMetric(values[0])
+	}
+
+	pidMax, err := readKernelValues("sys/kernel/pid_max", 1)
+	if err != nil {
+		return err
+	}
+	ch <- c.pidMax.mustNewConstMetric(pidMax[0])
+
+	// The fourth field of loadavg is <runnable>/<total> scheduling entities,
+	// i.e. the processes and threads, which all consume a pid.
+	data, err := os.ReadFile(procFilePath("loadavg"))
+	if err != nil {
+		return err
+	}
+	pids, err := parseLoadavgEntities(string(data))
+	if err != nil {
+		return err
+	}
+	ch <- c.pids.mustNewConstMetric(pids)
+	if pidMax[0] > 0 {
+		ch <- c.pidUsage.mustNewConstMetric(pids / pidMax[0])
+	}
+	return nil
+}
+
+// readKernelValues reads the first n whitespace separated numbers of a file
+// below /proc.
+func readKernelValues(name string, n int) ([]float64, error) {
+	data, err := os.ReadFile(procFilePath(name))
+	if err != nil {
+		return nil, err
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) < n {
+		return nil, fmt.Errorf("unexpected content in %s: %q", procFilePath(name), data)
+	}
+	values := make([]float64, n)
+	for i := range values {
+		if values[i], err = strconv.ParseFloat(fields[i], 64); err != nil {
+			return nil, fmt.Errorf("invalid value in %s: %w", procFilePath(name), err)
+		}
+	}
+	return values, nil
+}
+
+func parseLoadavgEntities(data string) (float64, error) {
+	fields := strings.Fields(data)
+	if len(fields) < 4 {
+		return 0, fmt.Errorf("unexpected content in %s", procFilePath("loadavg"))
+	}
+	_, total, ok := strings.Cut(fields[3], "/")
+	if !ok {
+		return 0, fmt.Errorf("unexpected scheduling entities %q in %s", fields[3], procFilePath("loadavg"))
+	}
+	return strconv.ParseFloat(total, 64)
+}
@@ -0,0 +1,142 @@
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/procfs"
+	"github.com/rs/zerolog/log"
+	"os"
+)
+
+const (
+	mdadmSubsystem = "md"
+)
+
+var (
+	// mdStates are the activity states reported by procfs for an md device.
+	mdStates = []string{"active", "inactive", "recovering", "resyncing", "checking"}
+
+	mdStateDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, mdadmSubsystem, "state"),
+		"Indicates the state of the md device.",
+		[]string{"device", "state"},
+		nil,
+	)
+
+	mdDisksDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, mdadmSubsystem, "disks"),
+		"Number of active/failed/spare/down disks of the md device.",
+		[]string{"device", "state"},
+		nil,
+	)
+
+	mdDisksRequiredDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, mdadmSubsystem, "disks_required"),
+		"Total number of disks the md device requires.",
+		[]string{"device"},
+		nil,
+	)
+
+	mdBlocksDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, mdadmSubsystem, "blocks"),
+		"Total number of 1KiB blocks of the md device.",
+		[]string{"device"},
+		nil,
+	)
+
+	mdBlocksSyncedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, mdadmSubsystem, "blocks_synced"),
+		"Number of 1KiB blocks of the md device that are in sync.",
+		[]string{"device"},
+		nil,
+	)
+
+	mdSyncProgressDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, mdadmSubsystem, "sync_progress_ratio"),
+		"Progress of the running resync, recovery or check of the md device.",
+		[]string{"device"},
+		nil,
+	)
+
+	mdSyncSpeedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, mdadmSubsystem, "sync_speed_bytes_per_second"),
+		"Speed of the running resync, recovery or check of the md device.",
+		[]string{"device"},
+		nil,
+	)
+
+	mdSyncFinishDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, mdadmSubsystem, "sync_finish_seconds"),
+		"Estimated time until the running resync, recovery or check of the md device finishes.",
+		[]string{"device"},
+		nil,
+	)
+)
+
+type mdadmCollector struct {
+	fs procfs.FS
+}
+
+func init() {
+	registerCollector("mdadm", defaultEnabled, NewMdadmCollector)
+}
+
+// NewMdadmCollector returns a new Collector exposing the software RAID
+// status of /proc/mdstat.
+// Docs from https://raid.wiki.kernel.org/index.php/Mdstat
+func NewMdadmCollector() (Collector, error) {
+	fs, err := procfs.NewFS(*procPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open procfs: %w", err)
+	}
+
+	return &mdadmCollector{fs: fs}, nil
+}
+
+func (c *mdadmCollector) Update(ch chan<- prometheus.Metric) error {
+	stats, err := c.fs.MDStat()
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			log.Debug().Msg("mdstat file does not exist")
+			return ErrNoData
+		}
+		return fmt.Errorf("error parsing mdstatus: %w", err)
+	}
+
+	for _, md := range stats {
+		for _, state := range mdStates {
+			ch <- prometheus.MustNewConstMetric(mdStateDesc, prometheus.GaugeValue,
+				boolToFloat(md.ActivityState == state), md.Name, state)
+		}
+
+		for _, disks := range []struct {
+			state string
+			count int64
+		}{
+			{"active", md.DisksActive},
+			{"failed", md.DisksFailed},
+			{"spare", md.DisksSpare},
+			{"down", md.DisksDown},
+		} {
+			ch <- prometheus.MustNewConstMetric(mdDisksDesc, prometheus.GaugeValue, float64(disks.count), md.Name, disks.state)
+		}
+
+		ch <- prometheus.MustNewConstMetric(mdDisksRequiredDesc, prometheus.GaugeValue, float64(md.DisksTotal), md.Name)
+		ch <- prometheus.MustNewConstMetric(mdBlocksDesc, prometheus.GaugeValue, float64(md.BlocksTotal), md.Name)
+		ch <- prometheus.MustNewConstMetric(mdBlocksSyncedDesc, prometheus.GaugeValue, float64(md.BlocksSynced), md.Name)
+
+		// procfs reports the progress in percent, the speed in KiB/s and the
+		// remaining time in minutes, all zero when no sync is running.
+		progress := 1.0
+		switch md.ActivityState {
+		case "recovering", "resyncing", "checking":
+			progress = md.BlocksSyncedPct / 100
+		}
+		ch <- prometheus.MustNewConstMetric(mdSyncProgressDesc, prometheus.GaugeValue, progress, md.Name)
+		ch <- prometheus.MustNewConstMetric(mdSyncSpeedDesc, prometheus.GaugeValue, md.BlocksSyncedSpeed*1024, md.Name)
+		ch <- prometheus.MustNewConstMetric(mdSyncFinishDesc, prometheus.GaugeValue, md.BlocksSyncedFinishTime*60, md.Name)
+	}
+
+	return nil
+}
@@ -0,0 +1,157 @@
+package collector
+
+import (
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/procfs"
+)
+
+const (
+	mountStatsSubsystem = "mountstats_nfs"
+)
+
+type mountStatsCollector struct {
+	proc procfs.Proc
+
+	age              *prometheus.Desc
+	readBytes        *prometheus.Desc
+	writeBytes       *prometheus.Desc
+	directReadBytes  *prometheus.Desc
+	directWriteBytes *prometheus.Desc
+
+	opRequests        *prometheus.Desc
+	opTransmissions   *prometheus.Desc
+	opRetransmissions *prometheus.Desc
+	opMajorTimeouts   *prometheus.Desc
+	opSentBytes       *prometheus.Desc
+	opReceivedBytes   *prometheus.Desc
+	opQueueTime       *prometheus.Desc
+	opResponseTime    *prometheus.Desc
+	opRequestTime     *prometheus.Desc
+	opErrors          *prometheus.Desc
+
+	transportSends             *prometheus.Desc
+	transportReceives          *prometheus.Desc
+	transportBadTransactionIDs *prometheus.Desc
+	transportMaxRPCSlotsUsed   *prometheus.Desc
+}
+
+func init() {
+	registerCollector("mountstats", defaultDisabled, NewMountStatsCollector)
+}
+
+// NewMountStatsCollector returns a new Collector exposing the per mount and
+// per operation NFS client statistics of /proc/self/mountstats.
+// Docs from https://utcc.utoronto.ca/~cks/space/blog/linux/NFSMountstatsIndex
+func NewMountStatsCollector() (Collector, error) {
+	fs, err := procfs.NewFS(*procPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open procfs: %w", err)
+	}
+	proc, err := fs.Self()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open /proc/self: %w", err)
+	}
+
+	labels := []string{"export", "mountpoint", "protocol"}
+	opLabels := []string{"export", "mountpoint", "protocol", "operation"}
+	desc := func(name, help string, labels []string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, mountStatsSubsystem, name), help, labels, nil)
+	}
+
+	return &mountStatsCollector{
+		proc: proc,
+
+		age:              desc("age_seconds", "The age of the NFS mount in seconds.", labels),
+		readBytes:        desc("read_bytes_total", "Number of bytes read using the read() syscall.", labels),
+		writeBytes:       desc("write_bytes_total", "Number of bytes written using the write() syscall.", labels),
+		directReadBytes:  desc("direct_read_bytes_total", "Number of bytes read using the read() syscall in O_DIRECT mode.", labels),
+		directWriteBytes: desc("direct_write_bytes_total", "Number of bytes written using the write() syscall in O_DIRECT mode.", labels),
+
+		opRequests:        desc("operations_requests_total", "Number of requests performed for a given operation.", opLabels),
+		opTransmissions:   desc("operations_transmissions_total", "Number of times an actual RPC request has been transmitted for a given operation.", opLabels),
+		opRetransmissions: desc("operations_retransmissions_total", "Number of times a request of a given operation had to be retransmitted.", opLabels),
+		opMajorTimeouts:   desc("operations_major_timeouts_total", "Number of times a request has had a major timeout for a given operation.", opLabels),
+		opSentBytes:       desc("operations_request_bytes_total", "Number of bytes sent for a given operation, including RPC headers and payload.", opLabels),
+		opReceivedBytes:   desc("operations_response_bytes_total", "Number of bytes received for a given operation, including RPC headers and payload.", opLabels),
+		opQueueTime:       desc("operations_queue_time_seconds_total", "Duration all requests spent queued for transmission for a given operation before they were sent, in seconds.", opLabels),
+		opResponseTime:    desc("operations_response_time_seconds_total", "Duration all requests took to get a reply back after a request for a given operation was transmitted, in seconds.", opLabels),
+		opRequestTime:     desc("operations_request_time_seconds_total", "Duration all requests took from when a request was enqueued to when it was completely handled for a given operation, in seconds.", opLabels),
+		opErrors:          desc("operations_errors_total", "Number of requests of a given operation that completed with an error status.", opLabels),
+
+		transportSends:             desc("transport_sends_total", "Number of RPC requests for this mount sent to the NFS server.", labels),
+		transportReceives:          desc("transport_receives_total", "Number of RPC responses for this mount received from the NFS server.", labels),
+		transportBadTransactionIDs: desc("transport_bad_transaction_ids_total", "Number of times the NFS server sent a response with a transaction ID unknown to this client.", labels),
+		transportMaxRPCSlotsUsed:   desc("transport_maximum_rpcs_slots", "Maximum number of simultaneously active RPC requests ever used.", labels),
+	}, nil
+}
+
+func (c *mountStatsCollector) Update(ch chan<- prometheus.Metric) error {
+	mounts, err := c.proc.MountStats()
+	if err != nil {
+		return fmt.Errorf("failed to parse mountstats: %w", err)
+	}
+
+	// The same export can be mounted more than once at the same mountpoint,
+	// e.g. when a mount is stacked on top of another one.
+	seen := map[[2]string]bool{}
+	exported := false
+	for _, m := range mounts {
+		stats, ok := m.Stats.(*procfs.MountStatsNFS)
+		if !ok {
+			continue
+		}
+		key := [2]string{m.Device, m.Mount}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		exported = true
+		c.updateNFSStats(ch, m, stats)
+	}
+
+	if !exported {
+		return ErrNoData
+	}
+	return nil
+}
+
+func (c *mountStatsCollector) updateNFSStats(ch chan<- prometheus.Metric, m *procfs.Mount, s *procfs.MountStatsNFS) {
+	protocol := s.Transport.Protocol
+	labels := []string{m.Device, m.Mount, protocol}
+
+	ch <- prometheus.MustNewConstMetric(c.age, prometheus.GaugeValue, s.Age.Seconds(), labels...)
+	ch <- prometheus.MustNewConstMetric(c.readBytes, prometheus.CounterValue, float64(s.Bytes.Read), labels...)
+	ch <- prometheus.MustNewConstMetric(c.writeBytes, prometheus.CounterValue, float64(s.Bytes.Write), labels...)
+	ch <- prometheus.MustNewConstMetric(c.directReadBytes, prometheus.CounterValue, float64(s.Bytes.DirectRead), labels...)
+	ch <- prometheus.MustNewConstMetric(c.directWriteBytes, prometheus.CounterValue, float64(s.Bytes.DirectWrite), labels...)
+
+	ch <- prometheus.MustNewConstMetric(c.transportSends, prometheus.CounterValue, float64(s.Transport.Sends), labels...)
+	ch <- prometheus.MustNewConstMetric(c.transportReceives, prometheus.CounterValue, float64(s.Transport.Receives), labels...)
+	ch <- prometheus.MustNewConstMetric(c.transportBadTransactionIDs, prometheus.CounterValue, float64(s.Transport.BadTransactionIDs), labels...)
+	ch <- prometheus.MustNewConstMetric(c.transportMaxRPCSlotsUsed, prometheus.GaugeValue, float64(s.Transport.MaximumRPCSlotsUsed), labels...)
+
+	for _, op := range s.Operations {
+		opLabels := []string{m.Device, m.Mount, protocol, op.Operation}
+
+		// Transmissions count every attempt, so a request that was sent
+		// three times adds two retransmissions.
+		var retransmissions uint64
+		if op.Transmissions > op.Requests {
+			retransmissions = op.Transmissions - op.Requests
+		}
+
+		ch <- prometheus.MustNewConstMetric(c.opRequests, prometheus.CounterValue, float64(op.Requests), opLabels...)
+		ch <- prometheus.MustNewConstMetric(c.opTransmissions, prometheus.CounterValue, float64(op.Transmissions), opLabels...)
+		ch <- prometheus.MustNewConstMetric(c.opRetransmissions, prometheus.CounterValue, float64(retransmissions), opLabels...)
+		ch <- prometheus.MustNewConstMetric(c.opMajorTimeouts, prometheus.CounterValue, float64(op.MajorTimeouts), opLabels...)
+		ch <- prometheus.MustNewConstMetric(c.opSentBytes, prometheus.CounterValue, float64(op.BytesSent), opLabels...)
+		ch <- prometheus.MustNewConstMetric(c.opReceivedBytes, prometheus.CounterValue, float64(op.BytesReceived), opLabels...)
+		// procfs names the RTT the response time and the execution time the
+		// request time.
+		ch <- prometheus.MustNewConstMetric(c.opQueueTime, prometheus.CounterValue, float64(op.CumulativeQueueMilliseconds)/1000, opLabels...)
+		ch <- prometheus.MustNewConstMetric(c.opResponseTime, prometheus.CounterValue, float64(op.CumulativeTotalResponseMilliseconds)/1000, opLabels...)
+		ch <- prometheus.MustNewConstMetric(c.opRequestTime, prometheus.CounterValue, float64(op.CumulativeTotalRequestMilliseconds)/1000, opLabels...)
+		ch <- prometheus.MustNewConstMetric(c.opErrors, prometheus.CounterValue, float64(op.Errors), opLabels...)
+	}
+}
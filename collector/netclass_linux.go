@@ -0,0 +1,127 @@
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/procfs/sysfs"
+	"github.com/rs/zerolog/log"
+	"os"
+	"sync"
+)
+
+type netClassCollector struct {
+	fs               sysfs.FS
+	subsystem        string
+	deviceFilter     deviceFilter
+	metricDescsMutex sync.Mutex
+	metricDescs      map[string]*prometheus.Desc
+}
+
+func init() {
+	registerCollector("netclass", defaultEnabled, NewNetClassCollector)
+}
+
+// NewNetClassCollector returns a new Collector exposing network class stats.
+// The devices are filtered with the netdev collector flags.
+func NewNetClassCollector() (Collector, error) {
+	fs, err := sysfs.NewFS(*sysPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sysfs: %w", err)
+	}
+	if *netdevDeviceExclude != "" && *netdevDeviceInclude != "" {
+		return nil, errors.New("device-exclude & device-include are mutually exclusive")
+	}
+	return &netClassCollector{
+		fs:           fs,
+		subsystem:    "network",
+		deviceFilter: newDeviceFilter(*netdevDeviceExclude, *netdevDeviceInclude),
+		metricDescs:  map[string]*prometheus.Desc{},
+	}, nil
+}
+
+func (c *netClassCollector) Update(ch chan<- prometheus.Metric) error {
+	devices, err := c.fs.NetClassDevices()
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			log.Debug().Msg("net class information not found, skipping")
+			return ErrNoData
+		}
+		return fmt.Errorf("could not get net class info: %w", err)
+	}
+
+	for _, device := range devices {
+		if c.deviceFilter.ignored(device) {
+			continue
+		}
+		ifaceInfo, err := c.fs.NetClassByIface(device)
+		if err != nil {
+			// The interface may have been removed since listing the devices.
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return fmt.Errorf("could not get net class info of %s: %w", device, err)
+		}
+
+		upDesc := c.metricDesc("up", "Value is 1 if operstate is 'up', 0 otherwise.", []string{"device"})
+		upValue := 0.0
+		if ifaceInfo.OperState == "up" {
+			upValue = 1.0
+		}
+		ch <- prometheus.MustNewConstMetric(upDesc, prometheus.GaugeValue, upValue, ifaceInfo.Name)
+
+		infoDesc := c.metricDesc("info", "Non-numeric data from /sys/class/net/<iface>, value is always 1.",
+			[]string{"device", "address", "broadcast", "duplex", "operstate", "ifalias"})
+		ch <- prometheus.MustNewConstMetric(infoDesc, prometheus.GaugeValue, 1.0,
+			ifaceInfo.Name, ifaceInfo.Address, ifaceInfo.Broadcast, ifaceInfo.Duplex, ifaceInfo.OperState, ifaceInfo.IfAlias)
+
+		c.pushMetric(ch, "address_assign_type", ifaceInfo.AddrAssignType, prometheus.GaugeValue, ifaceInfo.Name)
+		c.pushMetric(ch, "carrier", ifaceInfo.Carrier, prometheus.GaugeValue, ifaceInfo.Name)
+		c.pushMetric(ch, "carrier_changes_total", ifaceInfo.CarrierChanges, prometheus.CounterValue, ifaceInfo.Name)
+		c.pushMetric(ch, "carrier_up_changes_total", ifaceInfo.CarrierUpCount, prometheus.CounterValue, ifaceInfo.Name)
+		c.pushMetric(ch, "carrier_down_changes_total", ifaceInfo.CarrierDownCount, prometheus.CounterValue, ifaceInfo.Name)
+		c.pushMetric(ch, "device_id", ifaceInfo.DevID, prometheus.GaugeValue, ifaceInfo.Name)
+		c.pushMetric(ch, "dormant", ifaceInfo.Dormant, prometheus.GaugeValue, ifaceInfo.Name)
+		c.pushMetric(ch, "flags", ifaceInfo.Flags, prometheus.GaugeValue, ifaceInfo.Name)
+		c.pushMetric(ch, "iface_id", ifaceInfo.IfIndex, prometheus.GaugeValue, ifaceInfo.Name)
+		c.pushMetric(ch, "iface_link", ifaceInfo.IfLink, prometheus.GaugeValue, ifaceInfo.Name)
+		c.pushMetric(ch, "iface_link_mode", ifaceInfo.LinkMode, prometheus.GaugeValue, ifaceInfo.Name)
+		c.pushMetric(ch, "mtu_bytes", ifaceInfo.MTU, prometheus.GaugeValue, ifaceInfo.Name)
+		c.pushMetric(ch, "name_assign_type", ifaceInfo.NameAssignType, prometheus.GaugeValue, ifaceInfo.Name)
+		c.pushMetric(ch, "net_dev_group", ifaceInfo.NetDevGroup, prometheus.GaugeValue, ifaceInfo.Name)
+
+		// The speed is in Mbit/s and -1 for interfaces without a link.
+		if ifaceInfo.Speed != nil && *ifaceInfo.Speed >= 0 {
+			speedBytes := int64(*ifaceInfo.Speed * 1000 * 1000 / 8)
+			c.pushMetric(ch, "speed_bytes", &speedBytes, prometheus.GaugeValue, ifaceInfo.Name)
+		}
+
+		c.pushMetric(ch, "transmit_queue_length", ifaceInfo.TxQueueLen, prometheus.GaugeValue, ifaceInfo.Name)
+		c.pushMetric(ch, "protocol_type", ifaceInfo.Type, prometheus.GaugeValue, ifaceInfo.Name)
+	}
+	return nil
+}
+
+func (c *netClassCollector) pushMetric(ch chan<- prometheus.Metric, name string, value *int64, valueType prometheus.ValueType, device string) {
+	if value == nil {
+		return
+	}
+	desc := c.metricDesc(name, fmt.Sprintf("Network device property: %s", name), []string{"device"})
+	ch <- prometheus.MustNewConstMetric(desc, valueType, float64(*value), device)
+}
+
+func (c *netClassCollector) metricDesc(name, help string, labels []string) *prometheus.Desc {
+	c.metricDescsMutex.Lock()
+	defer c.metricDescsMutex.Unlock()
+
+	if _, ok := c.metricDescs[name]; !ok {
+		c.metricDescs[name] = prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, c.subsystem, name),
+			help,
+			labels,
+			nil,
+		)
+	}
+
+	return c.metricDescs[name]
+}
@@ -0,0 +1,185 @@
+package collector
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	netStatsSubsystem = "netstat"
+)
+
+var (
+	netStatFields = flag.String("collector.netstat.fields",
+		"^(.*_(InErrors|InErrs)|Ip_Forwarding|Ip_ForwDatagrams|Ip(6|Ext)_(InOctets|OutOctets)|Icmp6?_(InMsgs|OutMsgs)|TcpExt_(Listen.*|Syncookies.*|TCPSynRetrans|TCPTimeouts|TCPLostRetransmit|TCPFastRetrans|TCPSlowStartRetrans|TCPAbortOn.*|TCPOFOQueue|TCPBacklogDrop)|Tcp_(ActiveOpens|InSegs|OutSegs|OutRsts|PassiveOpens|RetransSegs|CurrEstab|EstabResets|AttemptFails)|Udp6?_(InDatagrams|OutDatagrams|NoPorts|RcvbufErrors|SndbufErrors))$",
+		"Regexp of fields to return for netstat collector.")
+
+	// netStatGauges are the fields of /proc/net/{netstat,snmp,snmp6} that are
+	// not monotonic counters.
+	netStatGauges = map[string]bool{
+		"Ip_Forwarding":    true,
+		"Ip_DefaultTTL":    true,
+		"Tcp_RtoAlgorithm": true,
+		"Tcp_RtoMin":       true,
+		"Tcp_RtoMax":       true,
+		"Tcp_MaxConn":      true,
+		"Tcp_CurrEstab":    true,
+	}
+)
+
+type netStatCollector struct {
+	fieldPattern *regexp.Regexp
+}
+
+func init() {
+	registerCollector("netstat", defaultEnabled, NewNetStatCollector)
+}
+
+// NewNetStatCollector returns a new Collector exposing network stats.
+func NewNetStatCollector() (Collector, error) {
+	pattern, err := regexp.Compile(*netStatFields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile --collector.netstat.fields: %w", err)
+	}
+	return &netStatCollector{
+		fieldPattern: pattern,
+	}, nil
+}
+
+func (c *netStatCollector) Update(ch chan<- prometheus.Metric) error {
+	netStats, err := getNetStats(procFilePath("net/netstat"))
+	if err != nil {
+		return fmt.Errorf("couldn't get netstats: %w", err)
+	}
+	snmpStats, err := getNetStats(procFilePath("net/snmp"))
+	if err != nil {
+		return fmt.Errorf("couldn't get SNMP stats: %w", err)
+	}
+	snmp6Stats, err := getSNMP6Stats(procFilePath("net/snmp6"))
+	if err != nil {
+		return fmt.Errorf("couldn't get SNMP6 stats: %w", err)
+	}
+	// Merge the results of snmpStats into netStats (collisions are possible, but
+	// we know that the keys are always unique for the given use case).
+	for k, v := range snmpStats {
+		netStats[k] = v
+	}
+	for k, v := range snmp6Stats {
+		netStats[k] = v
+	}
+	for protocol, protocolStats := range netStats {
+		for name, value := range protocolStats {
+			key := protocol + "_" + name
+			if !c.fieldPattern.MatchString(key) {
+				continue
+			}
+			v, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return fmt.Errorf("invalid value %s in netstats: %w", value, err)
+			}
+			valueType := prometheus.CounterValue
+			if netStatGauges[key] {
+				valueType = prometheus.GaugeValue
+			}
+			ch <- prometheus.MustNewConstMetric(
+				prometheus.NewDesc(
+					prometheus.BuildFQName(namespace, netStatsSubsystem, key),
+					fmt.Sprintf("Statistic %s%s.", protocol, name),
+					nil, nil,
+				),
+				valueType, v,
+			)
+		}
+	}
+	return nil
+}
+
+func getNetStats(fileName string) (map[string]map[string]string, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return parseNetStats(file, fileName)
+}
+
+// parseNetStats parses the header/value line pairs of /proc/net/netstat and
+// /proc/net/snmp, e.g. "Tcp: RtoAlgorithm RtoMin ..." followed by "Tcp: 1 200 ...".
+func parseNetStats(r io.Reader, fileName string) (map[string]map[string]string, error) {
+	var (
+		netStats = map[string]map[string]string{}
+		scanner  = bufio.NewScanner(r)
+	)
+
+	for scanner.Scan() {
+		nameParts := strings.Split(scanner.Text(), " ")
+		if !scanner.Scan() {
+			break
+		}
+		valueParts := strings.Split(scanner.Text(), " ")
+		// Remove trailing :.
+		protocol := strings.TrimSuffix(nameParts[0], ":")
+		if len(nameParts) != len(valueParts) {
+			return nil, fmt.Errorf("field count mismatch in %s: %s",
+				fileName, protocol)
+		}
+		netStats[protocol] = map[string]string{}
+		for i := 1; i < len(nameParts); i++ {
+			netStats[protocol][nameParts[i]] = valueParts[i]
+		}
+	}
+
+	return netStats, scanner.Err()
+}
+
+func getSNMP6Stats(fileName string) (map[string]map[string]string, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		// On systems with IPv6 disabled, this file won't exist.
+		// Do nothing.
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+	defer file.Close()
+
+	return parseSNMP6Stats(file)
+}
+
+// parseSNMP6Stats parses the "Ip6InReceives 123" lines of /proc/net/snmp6,
+// splitting the name after the protocol, which ends with the first "6".
+func parseSNMP6Stats(r io.Reader) (map[string]map[string]string, error) {
+	var (
+		netStats = map[string]map[string]string{}
+		scanner  = bufio.NewScanner(r)
+	)
+
+	for scanner.Scan() {
+		stat := strings.Fields(scanner.Text())
+		if len(stat) < 2 {
+			continue
+		}
+		// Expect to have "6" in metric name, skip line otherwise
+		if sixIndex := strings.Index(stat[0], "6"); sixIndex != -1 {
+			protocol := stat[0][:sixIndex+1]
+			name := stat[0][sixIndex+1:]
+			if _, present := netStats[protocol]; !present {
+				netStats[protocol] = map[string]string{}
+			}
+			netStats[protocol][name] = stat[1]
+		}
+	}
+
+	return netStats, scanner.Err()
+}
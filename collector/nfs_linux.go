@@ -0,0 +1,115 @@
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/procfs/nfs"
+	"github.com/rs/zerolog/log"
+	"os"
+	"reflect"
+)
+
+const (
+	nfsSubsystem = "nfs"
+)
+
+type nfsCollector struct {
+	fs                                nfs.FS
+	nfsNetReadsDesc                   *prometheus.Desc
+	nfsNetConnectionsDesc             *prometheus.Desc
+	nfsRPCOperationsDesc              *prometheus.Desc
+	nfsRPCRetransmissionsDesc         *prometheus.Desc
+	nfsRPCAuthenticationRefreshesDesc *prometheus.Desc
+	nfsProceduresDesc                 *prometheus.Desc
+}
+
+func init() {
+	registerCollector(nfsSubsystem, defaultEnabled, NewNfsCollector)
+}
+
+// NewNfsCollector returns a new Collector exposing the NFS client statistics
+// of /proc/net/rpc/nfs.
+func NewNfsCollector() (Collector, error) {
+	fs, err := nfs.NewFS(*procPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open procfs: %w", err)
+	}
+
+	return &nfsCollector{
+		fs: fs,
+		nfsNetReadsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, nfsSubsystem, "packets_total"),
+			"Total NFS network packets (sent+received) by protocol type.",
+			[]string{"protocol"},
+			nil,
+		),
+		nfsNetConnectionsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, nfsSubsystem, "connections_total"),
+			"Total number of NFS TCP connections.",
+			nil,
+			nil,
+		),
+		nfsRPCOperationsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, nfsSubsystem, "rpcs_total"),
+			"Total number of RPCs performed.",
+			nil,
+			nil,
+		),
+		nfsRPCRetransmissionsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, nfsSubsystem, "rpc_retransmissions_total"),
+			"Number of RPC retransmissions performed.",
+			nil,
+			nil,
+		),
+		nfsRPCAuthenticationRefreshesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, nfsSubsystem, "rpc_authentication_refreshes_total"),
+			"Number of RPC authentication refreshes performed.",
+			nil,
+			nil,
+		),
+		nfsProceduresDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, nfsSubsystem, "requests_total"),
+			"Number of NFS procedures invoked.",
+			[]string{"proto", "method"},
+			nil,
+		),
+	}, nil
+}
+
+func (c *nfsCollector) Update(ch chan<- prometheus.Metric) error {
+	stats, err := c.fs.ClientRPCStats()
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			log.Debug().Msg("Not collecting NFS metrics")
+			return ErrNoData
+		}
+		return fmt.Errorf("failed to retrieve nfs stats: %w", err)
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.nfsNetReadsDesc, prometheus.CounterValue, float64(stats.Network.UDPCount), "udp")
+	ch <- prometheus.MustNewConstMetric(c.nfsNetReadsDesc, prometheus.CounterValue, float64(stats.Network.TCPCount), "tcp")
+	ch <- prometheus.MustNewConstMetric(c.nfsNetConnectionsDesc, prometheus.CounterValue, float64(stats.Network.TCPConnect))
+
+	ch <- prometheus.MustNewConstMetric(c.nfsRPCOperationsDesc, prometheus.CounterValue, float64(stats.ClientRPC.RPCCount))
+	ch <- prometheus.MustNewConstMetric(c.nfsRPCRetransmissionsDesc, prometheus.CounterValue, float64(stats.ClientRPC.Retransmissions))
+	ch <- prometheus.MustNewConstMetric(c.nfsRPCAuthenticationRefreshesDesc, prometheus.CounterValue, float64(stats.ClientRPC.AuthRefreshes))
+
+	c.updateNFSRequests(ch, "2", reflect.ValueOf(stats.V2Stats))
+	c.updateNFSRequests(ch, "3", reflect.ValueOf(stats.V3Stats))
+	c.updateNFSRequests(ch, "4", reflect.ValueOf(stats.ClientV4Stats))
+	return nil
+}
+
+// updateNFSRequests exports every uint64 field of a procedure stats struct,
+// named like the procedure, as a request counter.
+func (c *nfsCollector) updateNFSRequests(ch chan<- prometheus.Metric, proto string, v reflect.Value) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		if field.Kind() != reflect.Uint64 {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(c.nfsProceduresDesc, prometheus.CounterValue,
+			float64(field.Uint()), proto, v.Type().Field(i).Name)
+	}
+}
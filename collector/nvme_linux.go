@@ -0,0 +1,136 @@
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/procfs/sysfs"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/exp/slices"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+const (
+	nvmeSubsystem = "nvme"
+)
+
+var (
+	// nvmeStates are the controller states of drivers/nvme/host/core.c.
+	nvmeStates = []string{"new", "live", "resetting", "connecting", "deleting", "deleting (no IO)", "dead"}
+
+	// Namespaces are named nvme<ctrl>n<ns>, or nvme<subsys>c<ctrl>n<ns> below
+	// the controller when native multipathing is used.
+	nvmeNamespacePattern = regexp.MustCompile(`^nvme\d+(?:c\d+)?n\d+$`)
+)
+
+type nvmeCollector struct {
+	fs sysfs.FS
+
+	info                      *prometheus.Desc
+	state                     *prometheus.Desc
+	namespaceInfo             *prometheus.Desc
+	namespaceCapacity         *prometheus.Desc
+	namespaceLogicalBlockSize *prometheus.Desc
+}
+
+func init() {
+	registerCollector(nvmeSubsystem, defaultEnabled, NewNVMeCollector)
+}
+
+// NewNVMeCollector returns a new Collector exposing the NVMe controllers and
+// namespaces of /sys/class/nvme.
+func NewNVMeCollector() (Collector, error) {
+	fs, err := sysfs.NewFS(*sysPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sysfs: %w", err)
+	}
+
+	namespaceLabels := []string{"controller", "device", "nsid"}
+	return &nvmeCollector{
+		fs: fs,
+		info: prometheus.NewDesc(prometheus.BuildFQName(namespace, nvmeSubsystem, "info"),
+			"Non-numeric data from /sys/class/nvme/<device>, value is always 1.",
+			[]string{"device", "firmware_revision", "model", "serial", "state"}, nil,
+		),
+		state: prometheus.NewDesc(prometheus.BuildFQName(namespace, nvmeSubsystem, "state"),
+			"Whether the NVMe controller is in the given state.", []string{"device", "state"}, nil,
+		),
+		namespaceInfo: prometheus.NewDesc(prometheus.BuildFQName(namespace, nvmeSubsystem, "namespace_info"),
+			"Non-numeric data of the NVMe namespace, value is always 1.",
+			append(namespaceLabels, "wwid", "ana_state"), nil,
+		),
+		namespaceCapacity: prometheus.NewDesc(prometheus.BuildFQName(namespace, nvmeSubsystem, "namespace_capacity_bytes"),
+			"Capacity of the NVMe namespace.", namespaceLabels, nil,
+		),
+		namespaceLogicalBlockSize: prometheus.NewDesc(prometheus.BuildFQName(namespace, nvmeSubsystem, "namespace_logical_block_size_bytes"),
+			"Logical block size of the NVMe namespace.", namespaceLabels, nil,
+		),
+	}, nil
+}
+
+func (c *nvmeCollector) Update(ch chan<- prometheus.Metric) error {
+	devices, err := c.fs.NVMeClass()
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			log.Debug().Msg("nvme statistics not found, skipping")
+			return ErrNoData
+		}
+		return fmt.Errorf("error obtaining NVMe class info: %w", err)
+	}
+
+	for _, device := range devices {
+		ch <- prometheus.MustNewConstMetric(c.info, prometheus.GaugeValue, 1,
+			device.Name, device.FirmwareRevision, device.Model, device.Serial, device.State)
+
+		states := nvmeStates
+		if !slices.Contains(states, device.State) {
+			states = append(states[:len(states):len(states)], device.State)
+		}
+		for _, s := range states {
+			ch <- prometheus.MustNewConstMetric(c.state, prometheus.GaugeValue, boolToFloat(s == device.State), device.Name, s)
+		}
+
+		if err := c.updateNamespaces(ch, device.Name); err != nil {
+			return fmt.Errorf("couldn't get namespaces of %s: %w", device.Name, err)
+		}
+	}
+	return nil
+}
+
+func (c *nvmeCollector) updateNamespaces(ch chan<- prometheus.Metric, controller string) error {
+	controllerPath := sysFilePath(filepath.Join("class", "nvme", controller))
+	entries, err := os.ReadDir(controllerPath)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if !nvmeNamespacePattern.MatchString(entry.Name()) {
+			continue
+		}
+		nsPath := filepath.Join(controllerPath, entry.Name())
+		nsid, err := readStringFromFile(filepath.Join(nsPath, "nsid"))
+		if err != nil {
+			continue
+		}
+		// wwid and ana_state are missing on older kernels and controllers
+		// without multipath support.
+		wwid, _ := readStringFromFile(filepath.Join(nsPath, "wwid"))
+		anaState, _ := readStringFromFile(filepath.Join(nsPath, "ana_state"))
+		ch <- prometheus.MustNewConstMetric(c.namespaceInfo, prometheus.GaugeValue, 1,
+			controller, entry.Name(), nsid, wwid, anaState)
+
+		// size is in 512 byte sectors regardless of the block size.
+		if sectors, err := readUintFromFile(filepath.Join(nsPath, "size")); err == nil {
+			ch <- prometheus.MustNewConstMetric(c.namespaceCapacity, prometheus.GaugeValue, float64(sectors*512),
+				controller, entry.Name(), nsid)
+		}
+		if blockSize, err := readUintFromFile(filepath.Join(nsPath, "queue", "logical_block_size")); err == nil {
+			ch <- prometheus.MustNewConstMetric(c.namespaceLogicalBlockSize, prometheus.GaugeValue, float64(blockSize),
+				controller, entry.Name(), nsid)
+		}
+	}
+	return nil
+}
@@ -11,12 +11,21 @@ var (
 	procPath   = flag.String("path.procfs", procfs.DefaultMountPoint, "procfs mountpoint.")
 	rootfsPath = flag.String("path.rootfs", "/", "procfs mountpoint.")
 	sysPath    = flag.String("path.sysfs", "/sys", "sysfs mountpoint.")
+	udevPath   = flag.String("path.udev.data", "/run/udev/data", "udev data path.")
 )
 
 func procFilePath(name string) string {
 	return filepath.Join(*procPath, name)
 }
 
+func sysFilePath(name string) string {
+	return filepath.Join(*sysPath, name)
+}
+
+func udevDataFilePath(name string) string {
+	return filepath.Join(*udevPath, name)
+}
+
 func rootfsFilePath(name string) string {
 	return filepath.Join(*rootfsPath, name)
 }
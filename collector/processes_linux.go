@@ -4,12 +4,20 @@ import (
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/procfs"
+	"sync"
+	"time"
 )
 
+// procSampleMaxAge is the age up to which a walk of /proc is shared between
+// the processes, proctop and processgroups collectors, so that a scrape walks
+// /proc once.
+const procSampleMaxAge = 500 * time.Millisecond
+
+// processCollector counts the processes, the pid limit is exported by the
+// kernel collector as node1s_kernel_pid_max.
 type processCollector struct {
 	fs      procfs.FS
 	pidUsed *prometheus.Desc
-	pidMax  *prometheus.Desc
 }
 
 func init() {
@@ -27,18 +35,133 @@ func NewProcessStatCollector() (Collector, error) {
 		pidUsed: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "pids"),
 			"Number of PIDs", nil, nil,
 		),
-		// cat /proc/sys/kernel/pid_max
-		//pidMax: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "max_processes"),
-		//	"Number of max PIDs limit", nil, nil,
-		//),
 	}, nil
 }
 
 func (c *processCollector) Update(ch chan<- prometheus.Metric) error {
-	procs, err := c.fs.AllProcs()
+	samples, _, err := sharedProcSampler.sample(c.fs)
 	if err != nil {
-		return errors.Wrapf(err, "unable to list all processes")
+		return err
 	}
-	ch <- prometheus.MustNewConstMetric(c.pidUsed, prometheus.GaugeValue, float64(len(procs)))
+	ch <- prometheus.MustNewConstMetric(c.pidUsed, prometheus.GaugeValue, float64(len(samples)))
 	return nil
 }
+
+// procSample is the state of a single process read during a walk of /proc.
+type procSample struct {
+	proc        procfs.Proc
+	pid         int
+	startTime   uint64
+	comm        string
+	cgroup      string
+	cpuTime     float64
+	rss         uint64
+	readBytes   uint64
+	writeBytes  uint64
+	threads     int
+	ctxSwitches uint64
+}
+
+// procSampleFields are the optional files read for every process.
+type procSampleFields struct {
+	io     bool
+	cgroup bool
+}
+
+// procSampler walks /proc for the processes, proctop and processgroups
+// collectors, reading the union of the files they require.
+type procSampler struct {
+	mutex   sync.Mutex
+	fields  procSampleFields
+	time    time.Time
+	samples []procSample
+}
+
+var sharedProcSampler = &procSampler{}
+
+// require adds optional files to read for every process, it is called by
+// the constructors of the collectors using the sampler.
+func (s *procSampler) require(fields procSampleFields) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.fields.io = s.fields.io || fields.io
+	s.fields.cgroup = s.fields.cgroup || fields.cgroup
+	s.time = time.Time{}
+}
+
+// sample returns the processes and the time they were read. The samples are
+// shared and must not be modified.
+func (s *procSampler) sample(fs procfs.FS) ([]procSample, time.Time, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if time.Since(s.time) < procSampleMaxAge {
+		return s.samples, s.time, nil
+	}
+	samples, err := sampleProcs(fs, s.fields)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	s.samples, s.time = samples, time.Now()
+	return s.samples, s.time, nil
+}
+
+// sampleProcs reads stat and status of every process, and io and cgroup when
+// selected by fields. Processes exiting during the walk are skipped, io is
+// left at zero when it is not readable, e.g. for processes of other users
+// when not running as root.
+func sampleProcs(fs procfs.FS, fields procSampleFields) ([]procSample, error) {
+	procs, err := fs.AllProcs()
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to list all processes")
+	}
+
+	samples := make([]procSample, 0, len(procs))
+	for _, p := range procs {
+		stat, err := p.Stat()
+		if err != nil {
+			continue
+		}
+		s := procSample{
+			proc:      p,
+			pid:       p.PID,
+			startTime: stat.Starttime,
+			comm:      stat.Comm,
+			cpuTime:   stat.CPUTime(),
+			rss:       uint64(stat.ResidentMemory()),
+			threads:   stat.NumThreads,
+		}
+		if status, err := p.NewStatus(); err == nil {
+			s.rss = status.VmRSS
+			s.ctxSwitches = status.TotalCtxtSwitches()
+		}
+		if fields.io {
+			if io, err := p.IO(); err == nil {
+				s.readBytes = io.ReadBytes
+				s.writeBytes = io.WriteBytes
+			}
+		}
+		if fields.cgroup {
+			if cgroups, err := p.Cgroups(); err == nil {
+				s.cgroup = procCgroupPath(cgroups)
+			}
+		}
+		samples = append(samples, s)
+	}
+	return samples, nil
+}
+
+// procCgroupPath returns the cgroup v2 path of a process, or the first v1
+// hierarchy on hosts without the unified hierarchy.
+func procCgroupPath(cgroups []procfs.Cgroup) string {
+	for _, cg := range cgroups {
+		if cg.HierarchyID == 0 {
+			return cg.Path
+		}
+	}
+	if len(cgroups) > 0 {
+		return cgroups[0].Path
+	}
+	return ""
+}
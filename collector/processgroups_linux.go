@@ -0,0 +1,266 @@
+package collector
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/procfs"
+	"gopkg.in/yaml.v2"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+const (
+	processGroupsSubsystem = "process_group"
+)
+
+var (
+	processGroupsConfigFile = flag.String("collector.processgroups.config", "", "YAML file defining the process groups, each with a name and any of comm, exe, cmdline and cgroup regexps.")
+)
+
+// processGroupConfig is a named group of processes. A process belongs to the
+// first group whose configured regexps all match.
+//
+//	groups:
+//	  - name: nginx
+//	    comm: ^nginx$
+//	  - name: java-app
+//	    exe: ^/usr/lib/jvm/
+//	    cmdline: -jar /opt/app/app\.jar
+//	    cgroup: ^/system\.slice/app\.service
+type processGroupConfig struct {
+	Name    string `yaml:"name"`
+	Comm    string `yaml:"comm"`
+	Exe     string `yaml:"exe"`
+	Cmdline string `yaml:"cmdline"`
+	Cgroup  string `yaml:"cgroup"`
+}
+
+type processGroupsFile struct {
+	Groups []processGroupConfig `yaml:"groups"`
+}
+
+type processGroupMatcher struct {
+	name    string
+	comm    *regexp.Regexp
+	exe     *regexp.Regexp
+	cmdline *regexp.Regexp
+	cgroup  *regexp.Regexp
+}
+
+// processGroupCounters are the monotonic totals of a group, accumulated from
+// the per process deltas so they do not drop when a member exits.
+type processGroupCounters struct {
+	cpuTime     float64
+	ctxSwitches uint64
+}
+
+type processGroupsCollector struct {
+	fs       procfs.FS
+	matchers []processGroupMatcher
+
+	cpu         *prometheus.Desc
+	ctxSwitches *prometheus.Desc
+	rss         *prometheus.Desc
+	fds         *prometheus.Desc
+	threads     *prometheus.Desc
+	count       *prometheus.Desc
+
+	mutex    sync.Mutex
+	previous map[procKey]procSample
+	counters map[string]*processGroupCounters
+}
+
+func init() {
+	registerCollector("processgroups", defaultDisabled, NewProcessGroupsCollector)
+}
+
+// NewProcessGroupsCollector returns a new Collector exposing the resource
+// usage of the configured process groups.
+func NewProcessGroupsCollector() (Collector, error) {
+	if *processGroupsConfigFile == "" {
+		return nil, errors.New("processgroups collector needs --collector.processgroups.config")
+	}
+	matchers, err := loadProcessGroups(*processGroupsConfigFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load process groups: %w", err)
+	}
+	fs, err := procfs.NewFS(*procPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open procfs: %w", err)
+	}
+
+	// The cgroup of every process is only read when a group matches on it.
+	var fields procSampleFields
+	for _, m := range matchers {
+		fields.cgroup = fields.cgroup || m.cgroup != nil
+	}
+	sharedProcSampler.require(fields)
+
+	labels := []string{"groupname"}
+	counters := make(map[string]*processGroupCounters, len(matchers))
+	for _, m := range matchers {
+		counters[m.name] = &processGroupCounters{}
+	}
+	return &processGroupsCollector{
+		fs:       fs,
+		matchers: matchers,
+		cpu: prometheus.NewDesc(prometheus.BuildFQName(namespace, processGroupsSubsystem, "cpu_seconds_total"),
+			"CPU seconds used by the processes of the group.", labels, nil,
+		),
+		ctxSwitches: prometheus.NewDesc(prometheus.BuildFQName(namespace, processGroupsSubsystem, "context_switches_total"),
+			"Voluntary and involuntary context switches of the processes of the group.", labels, nil,
+		),
+		rss: prometheus.NewDesc(prometheus.BuildFQName(namespace, processGroupsSubsystem, "resident_memory_bytes"),
+			"Resident memory of the processes of the group.", labels, nil,
+		),
+		fds: prometheus.NewDesc(prometheus.BuildFQName(namespace, processGroupsSubsystem, "open_fds"),
+			"Open file descriptors of the processes of the group.", labels, nil,
+		),
+		threads: prometheus.NewDesc(prometheus.BuildFQName(namespace, processGroupsSubsystem, "threads"),
+			"Threads of the processes of the group.", labels, nil,
+		),
+		count: prometheus.NewDesc(prometheus.BuildFQName(namespace, processGroupsSubsystem, "processes"),
+			"Number of processes in the group.", labels, nil,
+		),
+		previous: map[procKey]procSample{},
+		counters: counters,
+	}, nil
+}
+
+func loadProcessGroups(path string) ([]processGroupMatcher, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var config processGroupsFile
+	if err := yaml.UnmarshalStrict(data, &config); err != nil {
+		return nil, err
+	}
+
+	names := map[string]bool{}
+	matchers := make([]processGroupMatcher, 0, len(config.Groups))
+	for _, g := range config.Groups {
+		if g.Name == "" {
+			return nil, errors.New("process group without name")
+		}
+		if names[g.Name] {
+			return nil, fmt.Errorf("duplicate process group %s", g.Name)
+		}
+		names[g.Name] = true
+		if g.Comm == "" && g.Exe == "" && g.Cmdline == "" && g.Cgroup == "" {
+			return nil, fmt.Errorf("process group %s has no matcher", g.Name)
+		}
+
+		m := processGroupMatcher{name: g.Name}
+		for _, r := range []struct {
+			pattern string
+			re      **regexp.Regexp
+		}{
+			{g.Comm, &m.comm},
+			{g.Exe, &m.exe},
+			{g.Cmdline, &m.cmdline},
+			{g.Cgroup, &m.cgroup},
+		} {
+			if r.pattern == "" {
+				continue
+			}
+			if *r.re, err = regexp.Compile(r.pattern); err != nil {
+				return nil, fmt.Errorf("process group %s: %w", g.Name, err)
+			}
+		}
+		matchers = append(matchers, m)
+	}
+	return matchers, nil
+}
+
+// match returns whether the process matches all configured regexps. The
+// executable and command line are only read when a matcher needs them.
+func (m *processGroupMatcher) match(s procSample) bool {
+	if m.comm != nil && !m.comm.MatchString(s.comm) {
+		return false
+	}
+	if m.cgroup != nil && !m.cgroup.MatchString(s.cgroup) {
+		return false
+	}
+	if m.exe != nil {
+		exe, err := s.proc.Executable()
+		if err != nil || !m.exe.MatchString(exe) {
+			return false
+		}
+	}
+	if m.cmdline != nil {
+		cmdline, err := s.proc.CmdLine()
+		if err != nil || !m.cmdline.MatchString(strings.Join(cmdline, " ")) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *processGroupsCollector) Update(ch chan<- prometheus.Metric) error {
+	samples, _, err := sharedProcSampler.sample(c.fs)
+	if err != nil {
+		return err
+	}
+
+	type groupGauges struct {
+		rss     uint64
+		fds     int
+		threads int
+		count   int
+	}
+	gauges := make(map[string]*groupGauges, len(c.matchers))
+	for _, m := range c.matchers {
+		gauges[m.name] = &groupGauges{}
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	current := make(map[procKey]procSample, len(samples))
+	for _, s := range samples {
+		for _, m := range c.matchers {
+			if !m.match(s) {
+				continue
+			}
+			key := procKey{s.pid, s.startTime}
+			current[key] = s
+
+			g := gauges[m.name]
+			g.rss += s.rss
+			g.threads += s.threads
+			g.count++
+			if fds, err := s.proc.FileDescriptorsLen(); err == nil {
+				g.fds += fds
+			}
+
+			// New processes contribute their whole usage, known ones the
+			// usage since the previous scrape.
+			counters := c.counters[m.name]
+			prev := c.previous[key]
+			if s.cpuTime >= prev.cpuTime {
+				counters.cpuTime += s.cpuTime - prev.cpuTime
+			}
+			if s.ctxSwitches >= prev.ctxSwitches {
+				counters.ctxSwitches += s.ctxSwitches - prev.ctxSwitches
+			}
+			break
+		}
+	}
+	c.previous = current
+
+	for _, m := range c.matchers {
+		g, counters := gauges[m.name], c.counters[m.name]
+		ch <- prometheus.MustNewConstMetric(c.cpu, prometheus.CounterValue, counters.cpuTime, m.name)
+		ch <- prometheus.MustNewConstMetric(c.ctxSwitches, prometheus.CounterValue, float64(counters.ctxSwitches), m.name)
+		ch <- prometheus.MustNewConstMetric(c.rss, prometheus.GaugeValue, float64(g.rss), m.name)
+		ch <- prometheus.MustNewConstMetric(c.fds, prometheus.GaugeValue, float64(g.fds), m.name)
+		ch <- prometheus.MustNewConstMetric(c.threads, prometheus.GaugeValue, float64(g.threads), m.name)
+		ch <- prometheus.MustNewConstMetric(c.count, prometheus.GaugeValue, float64(g.count), m.name)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package collector
+
+import (
+	"flag"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/procfs"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	procTopSubsystem = "proctop"
+)
+
+var (
+	procTopCount = flag.Int("collector.proctop.count", 10, "Number of processes to export for each of the top CPU, memory and IO lists.")
+)
+
+// procKey identifies a process across scrapes, the start time guards
+// against pid reuse.
+type procKey struct {
+	pid       int
+	startTime uint64
+}
+
+type procTopCollector struct {
+	fs    procfs.FS
+	count int
+	cpu   *prometheus.Desc
+	rss   *prometheus.Desc
+	io    *prometheus.Desc
+
+	mutex    sync.Mutex
+	previous map[procKey]procSample
+	lastTime time.Time
+	metrics  []prometheus.Metric
+}
+
+// procDelta is the usage of a process since the previous scrape.
+type procDelta struct {
+	procSample
+	cpuRate float64
+	ioRate  float64
+}
+
+func init() {
+	registerCollector(procTopSubsystem, defaultDisabled, NewProcTopCollector)
+}
+
+// NewProcTopCollector returns a new Collector exposing the processes using the
+// most CPU, memory and IO since the previous scrape.
+func NewProcTopCollector() (Collector, error) {
+	fs, err := procfs.NewFS(*procPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open procfs: %w", err)
+	}
+	if *procTopCount < 1 {
+		return nil, fmt.Errorf("--collector.proctop.count must be at least 1")
+	}
+	sharedProcSampler.require(procSampleFields{io: true, cgroup: true})
+
+	labels := []string{"pid", "comm", "cgroup"}
+	return &procTopCollector{
+		fs:    fs,
+		count: *procTopCount,
+		cpu: prometheus.NewDesc(prometheus.BuildFQName(namespace, procTopSubsystem, "cpu_usage_ratio"),
+			"CPU seconds per second used by the top processes by CPU since the previous scrape.", labels, nil,
+		),
+		rss: prometheus.NewDesc(prometheus.BuildFQName(namespace, procTopSubsystem, "resident_memory_bytes"),
+			"Resident memory of the top processes by memory.", labels, nil,
+		),
+		io: prometheus.NewDesc(prometheus.BuildFQName(namespace, procTopSubsystem, "io_bytes_per_second"),
+			"Bytes read and written per second by the top processes by IO since the previous scrape.", labels, nil,
+		),
+		previous: map[procKey]procSample{},
+	}, nil
+}
+
+func (c *procTopCollector) Update(ch chan<- prometheus.Metric) error {
+	samples, now, err := sharedProcSampler.sample(c.fs)
+	if err != nil {
+		return err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	// Scrapes sharing a sample get the metrics computed for it, computing
+	// the rates again would divide by zero elapsed time.
+	if now.After(c.lastTime) {
+		c.metrics = c.computeMetrics(samples, now.Sub(c.lastTime).Seconds())
+		c.previous = make(map[procKey]procSample, len(samples))
+		for _, s := range samples {
+			c.previous[procKey{s.pid, s.startTime}] = s
+		}
+		c.lastTime = now
+	}
+	for _, m := range c.metrics {
+		ch <- m
+	}
+	return nil
+}
+
+// computeMetrics returns the top processes of a sample, with the rates since
+// the previous sample taken elapsed seconds before. It must be called with
+// the mutex held.
+func (c *procTopCollector) computeMetrics(samples []procSample, elapsed float64) []prometheus.Metric {
+	deltas := make([]procDelta, 0, len(samples))
+	for _, s := range samples {
+		d := procDelta{procSample: s}
+		// Processes started since the previous sample have no rate yet.
+		if prev, ok := c.previous[procKey{s.pid, s.startTime}]; ok && elapsed > 0 {
+			d.cpuRate = (s.cpuTime - prev.cpuTime) / elapsed
+			d.ioRate = (float64(s.readBytes+s.writeBytes) - float64(prev.readBytes+prev.writeBytes)) / elapsed
+		}
+		deltas = append(deltas, d)
+	}
+
+	var metrics []prometheus.Metric
+	for _, d := range c.top(deltas, func(d procDelta) float64 { return d.cpuRate }) {
+		metrics = append(metrics, prometheus.MustNewConstMetric(c.cpu, prometheus.GaugeValue, d.cpuRate, strconv.Itoa(d.pid), d.comm, d.cgroup))
+	}
+	for _, d := range c.top(deltas, func(d procDelta) float64 { return float64(d.rss) }) {
+		metrics = append(metrics, prometheus.MustNewConstMetric(c.rss, prometheus.GaugeValue, float64(d.rss), strconv.Itoa(d.pid), d.comm, d.cgroup))
+	}
+	for _, d := range c.top(deltas, func(d procDelta) float64 { return d.ioRate }) {
+		metrics = append(metrics, prometheus.MustNewConstMetric(c.io, prometheus.GaugeValue, d.ioRate, strconv.Itoa(d.pid), d.comm, d.cgroup))
+	}
+	return metrics
+}
+
+// top returns the processes with the highest non zero values.
+func (c *procTopCollector) top(deltas []procDelta, value func(procDelta) float64) []procDelta {
+	sort.Slice(deltas, func(i, j int) bool {
+		return value(deltas[i]) > value(deltas[j])
+	})
+	n := 0
+	for n < len(deltas) && n < c.count && value(deltas[n]) > 0 {
+		n++
+	}
+	return deltas[:n]
+}
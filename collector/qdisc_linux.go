@@ -0,0 +1,236 @@
+package collector
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/josharian/native"
+	"github.com/jsimonetti/rtnetlink"
+	"github.com/mdlayher/netlink"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	qdiscSubsystem = "qdisc"
+
+	// sizeof(struct tcmsg)
+	tcMsgLen = 20
+
+	// Attributes of include/uapi/linux/rtnetlink.h and gen_stats.h.
+	tcaKind         = 1
+	tcaStats        = 3
+	tcaStats2       = 7
+	tcaStatsBasic   = 1
+	tcaStatsQueue   = 3
+	tcaStatsPkt64   = 8
+	tcHandleRoot    = 0xffffffff
+	tcHandleIngress = 0xfffffff1
+)
+
+var (
+	qdiscDeviceInclude = flag.String("collector.qdisc.device-include", "", "Regexp of qdisc devices to include (mutually exclusive to device-exclude).")
+	qdiscDeviceExclude = flag.String("collector.qdisc.device-exclude", "", "Regexp of qdisc devices to exclude (mutually exclusive to device-include).")
+)
+
+// qdiscStats are the statistics of one queueing discipline.
+type qdiscStats struct {
+	ifIndex    uint32
+	handle     uint32
+	parent     uint32
+	kind       string
+	bytes      uint64
+	packets    uint64
+	drops      uint32
+	overlimits uint32
+	requeues   uint32
+	backlog    uint32
+	qlen       uint32
+}
+
+type qdiscCollector struct {
+	deviceFilter deviceFilter
+	bytes        *prometheus.Desc
+	packets      *prometheus.Desc
+	drops        *prometheus.Desc
+	overlimits   *prometheus.Desc
+	requeues     *prometheus.Desc
+	backlog      *prometheus.Desc
+	qlen         *prometheus.Desc
+}
+
+func init() {
+	registerCollector(qdiscSubsystem, defaultDisabled, NewQdiscCollector)
+}
+
+// NewQdiscCollector returns a new Collector exposing the statistics of the
+// traffic control queueing disciplines.
+func NewQdiscCollector() (Collector, error) {
+	if *qdiscDeviceExclude != "" && *qdiscDeviceInclude != "" {
+		return nil, errors.New("device-exclude & device-include are mutually exclusive")
+	}
+
+	labels := []string{"device", "kind", "handle", "parent"}
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, qdiscSubsystem, name), help, labels, nil)
+	}
+	return &qdiscCollector{
+		deviceFilter: newDeviceFilter(*qdiscDeviceExclude, *qdiscDeviceInclude),
+		bytes:        desc("bytes_total", "Number of bytes sent by the queueing discipline."),
+		packets:      desc("packets_total", "Number of packets sent by the queueing discipline."),
+		drops:        desc("drops_total", "Number of packets dropped by the queueing discipline."),
+		overlimits:   desc("overlimits_total", "Number of times the queueing discipline was over its limit."),
+		requeues:     desc("requeues_total", "Number of packets dequeued, not transmitted and requeued."),
+		backlog:      desc("backlog_bytes", "Number of bytes currently in the queue."),
+		qlen:         desc("queue_length", "Number of packets currently in the queue."),
+	}, nil
+}
+
+func (c *qdiscCollector) Update(ch chan<- prometheus.Metric) error {
+	conn, err := netlink.Dial(unix.NETLINK_ROUTE, nil)
+	if err != nil {
+		return fmt.Errorf("couldn't connect netlink: %w", err)
+	}
+	defer conn.Close()
+
+	// An all zero struct tcmsg dumps the qdiscs of every interface.
+	msgs, err := conn.Execute(netlink.Message{
+		Header: netlink.Header{
+			Type:  unix.RTM_GETQDISC,
+			Flags: netlink.Request | netlink.Dump,
+		},
+		Data: make([]byte, tcMsgLen),
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't get qdiscs: %w", err)
+	}
+
+	rtConn, err := rtnetlink.Dial(nil)
+	if err != nil {
+		return fmt.Errorf("couldn't connect rtnetlink: %w", err)
+	}
+	defer rtConn.Close()
+
+	names, err := linkNames(rtConn)
+	if err != nil {
+		return fmt.Errorf("couldn't get links: %w", err)
+	}
+
+	for _, m := range msgs {
+		s, err := parseQdiscMessage(m.Data)
+		if err != nil {
+			return fmt.Errorf("couldn't parse qdisc: %w", err)
+		}
+
+		device, ok := names[s.ifIndex]
+		if !ok {
+			log.Debug().Msgf("couldn't get interface %d", s.ifIndex)
+			continue
+		}
+		if c.deviceFilter.ignored(device) {
+			continue
+		}
+
+		labels := []string{device, s.kind, formatTCHandle(s.handle), formatTCHandle(s.parent)}
+		ch <- prometheus.MustNewConstMetric(c.bytes, prometheus.CounterValue, float64(s.bytes), labels...)
+		ch <- prometheus.MustNewConstMetric(c.packets, prometheus.CounterValue, float64(s.packets), labels...)
+		ch <- prometheus.MustNewConstMetric(c.drops, prometheus.CounterValue, float64(s.drops), labels...)
+		ch <- prometheus.MustNewConstMetric(c.overlimits, prometheus.CounterValue, float64(s.overlimits), labels...)
+		ch <- prometheus.MustNewConstMetric(c.requeues, prometheus.CounterValue, float64(s.requeues), labels...)
+		ch <- prometheus.MustNewConstMetric(c.backlog, prometheus.GaugeValue, float64(s.backlog), labels...)
+		ch <- prometheus.MustNewConstMetric(c.qlen, prometheus.GaugeValue, float64(s.qlen), labels...)
+	}
+	return nil
+}
+
+// parseQdiscMessage parses a struct tcmsg followed by the TCA_KIND and the
+// TCA_STATS2 attributes, falling back to the older TCA_STATS struct tc_stats.
+func parseQdiscMessage(data []byte) (qdiscStats, error) {
+	var s qdiscStats
+	if len(data) < tcMsgLen {
+		return s, fmt.Errorf("short tcmsg: %d bytes", len(data))
+	}
+	s.ifIndex = native.Endian.Uint32(data[4:8])
+	s.handle = native.Endian.Uint32(data[8:12])
+	s.parent = native.Endian.Uint32(data[12:16])
+
+	ad, err := netlink.NewAttributeDecoder(data[tcMsgLen:])
+	if err != nil {
+		return s, err
+	}
+	var (
+		hasStats2 bool
+		legacy    []byte
+	)
+	for ad.Next() {
+		switch ad.Type() {
+		case tcaKind:
+			s.kind = ad.String()
+		case tcaStats2:
+			hasStats2 = true
+			ad.Nested(func(nad *netlink.AttributeDecoder) error {
+				for nad.Next() {
+					b := nad.Bytes()
+					switch nad.Type() {
+					case tcaStatsBasic:
+						// struct gnet_stats_basic: bytes u64, packets u32.
+						if len(b) >= 12 {
+							s.bytes = native.Endian.Uint64(b[0:8])
+							if s.packets == 0 {
+								s.packets = uint64(native.Endian.Uint32(b[8:12]))
+							}
+						}
+					case tcaStatsPkt64:
+						if len(b) >= 8 {
+							s.packets = native.Endian.Uint64(b[0:8])
+						}
+					case tcaStatsQueue:
+						// struct gnet_stats_queue: qlen, backlog, drops,
+						// requeues, overlimits.
+						if len(b) >= 20 {
+							s.qlen = native.Endian.Uint32(b[0:4])
+							s.backlog = native.Endian.Uint32(b[4:8])
+							s.drops = native.Endian.Uint32(b[8:12])
+							s.requeues = native.Endian.Uint32(b[12:16])
+							s.overlimits = native.Endian.Uint32(b[16:20])
+						}
+					}
+				}
+				return nil
+			})
+		case tcaStats:
+			legacy = ad.Bytes()
+		}
+	}
+	if err := ad.Err(); err != nil {
+		return s, err
+	}
+
+	// struct tc_stats: bytes u64, packets, drops, overlimits, bps, pps, qlen,
+	// backlog u32.
+	if !hasStats2 && len(legacy) >= 36 {
+		s.bytes = native.Endian.Uint64(legacy[0:8])
+		s.packets = uint64(native.Endian.Uint32(legacy[8:12]))
+		s.drops = native.Endian.Uint32(legacy[12:16])
+		s.overlimits = native.Endian.Uint32(legacy[16:20])
+		s.qlen = native.Endian.Uint32(legacy[28:32])
+		s.backlog = native.Endian.Uint32(legacy[32:36])
+	}
+	return s, nil
+}
+
+// formatTCHandle formats a qdisc handle like tc(8), e.g. 8001: or 1:10.
+func formatTCHandle(handle uint32) string {
+	switch handle {
+	case tcHandleRoot:
+		return "root"
+	case tcHandleIngress:
+		return "ingress"
+	}
+	major, minor := handle>>16, handle&0xffff
+	if minor == 0 {
+		return fmt.Sprintf("%x:", major)
+	}
+	return fmt.Sprintf("%x:%x", major, minor)
+}
@@ -0,0 +1,103 @@
+package collector
+
+import (
+	"github.com/josharian/native"
+	"github.com/mdlayher/netlink"
+	"testing"
+)
+
+// testTCMsg encodes a struct tcmsg followed by the attributes set by encode.
+func testTCMsg(t *testing.T, ifIndex, handle, parent uint32, encode func(ae *netlink.AttributeEncoder)) []byte {
+	t.Helper()
+	b := make([]byte, tcMsgLen)
+	native.Endian.PutUint32(b[4:8], ifIndex)
+	native.Endian.PutUint32(b[8:12], handle)
+	native.Endian.PutUint32(b[12:16], parent)
+
+	ae := netlink.NewAttributeEncoder()
+	encode(ae)
+	attrs, err := ae.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return append(b, attrs...)
+}
+
+// testNativeUint32s encodes values in native byte order, prefixed by a u64.
+func testNativeUint32s(u64 uint64, values ...uint32) []byte {
+	b := make([]byte, 8+4*len(values))
+	native.Endian.PutUint64(b[0:8], u64)
+	for i, v := range values {
+		native.Endian.PutUint32(b[8+4*i:], v)
+	}
+	return b
+}
+
+func TestParseQdiscMessage(t *testing.T) {
+	// struct gnet_stats_queue: qlen, backlog, drops, requeues, overlimits.
+	queue := testNativeUint32s(0, 3, 1500, 7, 2, 9)[8:]
+
+	tests := []struct {
+		name string
+		data []byte
+		want qdiscStats
+	}{
+		{
+			name: "stats2",
+			data: testTCMsg(t, 2, 0x80010000, tcHandleRoot, func(ae *netlink.AttributeEncoder) {
+				ae.String(tcaKind, "fq_codel")
+				// The legacy stats are ignored when TCA_STATS2 is present.
+				ae.Bytes(tcaStats, testNativeUint32s(1, 1, 1, 1, 1, 1, 1, 1))
+				ae.Nested(tcaStats2, func(nae *netlink.AttributeEncoder) error {
+					// struct gnet_stats_basic: bytes u64, packets u32, padded.
+					nae.Bytes(tcaStatsBasic, testNativeUint32s(123456, 4000, 0))
+					nae.Uint64(tcaStatsPkt64, 1<<33)
+					nae.Bytes(tcaStatsQueue, queue)
+					return nil
+				})
+			}),
+			want: qdiscStats{
+				ifIndex: 2, handle: 0x80010000, parent: tcHandleRoot, kind: "fq_codel",
+				bytes: 123456, packets: 1 << 33, qlen: 3, backlog: 1500, drops: 7, requeues: 2, overlimits: 9,
+			},
+		},
+		{
+			name: "stats2 without packets64",
+			data: testTCMsg(t, 2, 0x10000, tcHandleRoot, func(ae *netlink.AttributeEncoder) {
+				ae.String(tcaKind, "htb")
+				ae.Nested(tcaStats2, func(nae *netlink.AttributeEncoder) error {
+					nae.Bytes(tcaStatsBasic, testNativeUint32s(2048, 16, 0))
+					return nil
+				})
+			}),
+			want: qdiscStats{ifIndex: 2, handle: 0x10000, parent: tcHandleRoot, kind: "htb", bytes: 2048, packets: 16},
+		},
+		{
+			name: "legacy tc_stats",
+			data: testTCMsg(t, 1, 0, tcHandleIngress, func(ae *netlink.AttributeEncoder) {
+				ae.String(tcaKind, "ingress")
+				// struct tc_stats: bytes u64, packets, drops, overlimits,
+				// bps, pps, qlen, backlog.
+				ae.Bytes(tcaStats, testNativeUint32s(999, 10, 4, 5, 100, 1, 6, 600))
+			}),
+			want: qdiscStats{
+				ifIndex: 1, parent: tcHandleIngress, kind: "ingress",
+				bytes: 999, packets: 10, drops: 4, overlimits: 5, qlen: 6, backlog: 600,
+			},
+		},
+	}
+	for _, tt := range tests {
+		got, err := parseQdiscMessage(tt.data)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: want %+v, got %+v", tt.name, tt.want, got)
+		}
+	}
+
+	if _, err := parseQdiscMessage(make([]byte, tcMsgLen-1)); err == nil {
+		t.Error("want error for short tcmsg")
+	}
+}
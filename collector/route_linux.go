@@ -0,0 +1,162 @@
+package collector
+
+import (
+	"fmt"
+	"github.com/jsimonetti/rtnetlink"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/unix"
+	"net"
+	"strconv"
+)
+
+const (
+	routeSubsystem = "route"
+)
+
+// routeTables are the names of the reserved routing tables of
+// include/uapi/linux/rtnetlink.h, other tables are labeled with their id.
+var routeTables = map[uint32]string{
+	unix.RT_TABLE_DEFAULT: "default",
+	unix.RT_TABLE_MAIN:    "main",
+	unix.RT_TABLE_LOCAL:   "local",
+}
+
+type routeCollector struct {
+	routes      *prometheus.Desc
+	defaultInfo *prometheus.Desc
+}
+
+type routeKey struct {
+	table  string
+	device string
+	family string
+}
+
+func init() {
+	registerCollector(routeSubsystem, defaultEnabled, NewRouteCollector)
+}
+
+// NewRouteCollector returns a new Collector exposing the number of routes per
+// table and device and the default routes.
+func NewRouteCollector() (Collector, error) {
+	return &routeCollector{
+		routes: prometheus.NewDesc(prometheus.BuildFQName(namespace, routeSubsystem, "routes"),
+			"Number of routes by table, device and address family.", []string{"table", "device", "family"}, nil,
+		),
+		defaultInfo: prometheus.NewDesc(prometheus.BuildFQName(namespace, routeSubsystem, "default_info"),
+			"Default routes with their gateway and priority, value is always 1.",
+			[]string{"table", "device", "family", "gateway", "priority"}, nil,
+		),
+	}, nil
+}
+
+func (c *routeCollector) Update(ch chan<- prometheus.Metric) error {
+	conn, err := rtnetlink.Dial(nil)
+	if err != nil {
+		return fmt.Errorf("couldn't connect rtnetlink: %w", err)
+	}
+	defer conn.Close()
+
+	routes, err := conn.Route.List()
+	if err != nil {
+		return fmt.Errorf("couldn't get routes: %w", err)
+	}
+
+	names, err := linkNames(conn)
+	if err != nil {
+		return fmt.Errorf("couldn't get links: %w", err)
+	}
+	deviceName := func(index uint32) string {
+		if index == 0 {
+			return ""
+		}
+		if name, ok := names[index]; ok {
+			return name
+		}
+		return strconv.FormatUint(uint64(index), 10)
+	}
+
+	counts := map[routeKey]float64{}
+	defaults := map[[5]string]bool{}
+	for _, r := range routes {
+		family := routeFamily(r.Family)
+		if family == "" {
+			continue
+		}
+		table := routeTable(r)
+
+		// Multipath routes have no output interface, but one per next hop.
+		type nextHop struct {
+			device  string
+			gateway net.IP
+		}
+		hops := []nextHop{{deviceName(r.Attributes.OutIface), r.Attributes.Gateway}}
+		if len(r.Attributes.Multipath) > 0 {
+			hops = hops[:0]
+			for _, h := range r.Attributes.Multipath {
+				hops = append(hops, nextHop{deviceName(h.Hop.IfIndex), h.Gateway})
+			}
+		}
+
+		for _, h := range hops {
+			counts[routeKey{table, h.device, family}]++
+			if r.DstLength == 0 && r.Type == unix.RTN_UNICAST {
+				gateway := ""
+				if h.gateway != nil {
+					gateway = h.gateway.String()
+				}
+				defaults[[5]string{table, h.device, family, gateway, strconv.FormatUint(uint64(r.Attributes.Priority), 10)}] = true
+			}
+		}
+	}
+
+	// Routes differing only in e.g. TOS or source would otherwise be
+	// exported twice.
+	for labels := range defaults {
+		ch <- prometheus.MustNewConstMetric(c.defaultInfo, prometheus.GaugeValue, 1, labels[:]...)
+	}
+	for key, count := range counts {
+		ch <- prometheus.MustNewConstMetric(c.routes, prometheus.GaugeValue, count, key.table, key.device, key.family)
+	}
+	return nil
+}
+
+// linkNames returns the interface names by index from a single RTM_GETLINK
+// dump, net.InterfaceByIndex dumps all links on every call.
+func linkNames(conn *rtnetlink.Conn) (map[uint32]string, error) {
+	links, err := conn.Link.List()
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[uint32]string, len(links))
+	for _, l := range links {
+		if l.Attributes != nil {
+			names[l.Index] = l.Attributes.Name
+		}
+	}
+	return names, nil
+}
+
+// routeTable returns the table name of a route. Table ids above 255 are only
+// set in the RTA_TABLE attribute.
+func routeTable(r rtnetlink.RouteMessage) string {
+	id := r.Attributes.Table
+	if id == 0 {
+		id = uint32(r.Table)
+	}
+	if name, ok := routeTables[id]; ok {
+		return name
+	}
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+func routeFamily(family uint8) string {
+	switch family {
+	case unix.AF_INET:
+		return "inet"
+	case unix.AF_INET6:
+		return "inet6"
+	default:
+		return ""
+	}
+}
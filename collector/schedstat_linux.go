@@ -0,0 +1,92 @@
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/procfs"
+	"github.com/rs/zerolog/log"
+	"os"
+)
+
+const (
+	nsPerSec = 1e9
+)
+
+var (
+	runningSecondsTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "schedstat", "running_seconds_total"),
+		"Number of seconds CPU spent running a process.",
+		[]string{"cpu"},
+		nil,
+	)
+
+	waitingSecondsTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "schedstat", "waiting_seconds_total"),
+		"Number of seconds processes spent waiting in the run queue of this CPU.",
+		[]string{"cpu"},
+		nil,
+	)
+
+	timeslicesTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "schedstat", "timeslices_total"),
+		"Number of timeslices executed by CPU.",
+		[]string{"cpu"},
+		nil,
+	)
+)
+
+type schedstatCollector struct {
+	fs procfs.FS
+}
+
+func init() {
+	registerCollector("schedstat", defaultEnabled, NewSchedstatCollector)
+}
+
+// NewSchedstatCollector returns a new Collector exposing task scheduler statistics.
+// Docs from https://www.kernel.org/doc/Documentation/scheduler/sched-stats.txt
+func NewSchedstatCollector() (Collector, error) {
+	fs, err := procfs.NewFS(*procPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open procfs: %w", err)
+	}
+
+	return &schedstatCollector{fs: fs}, nil
+}
+
+func (c *schedstatCollector) Update(ch chan<- prometheus.Metric) error {
+	stats, err := c.fs.Schedstat()
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			log.Debug().Msg("schedstat file does not exist")
+			return ErrNoData
+		}
+		return err
+	}
+
+	for _, cpu := range stats.CPUs {
+		ch <- prometheus.MustNewConstMetric(
+			runningSecondsTotal,
+			prometheus.CounterValue,
+			float64(cpu.RunningNanoseconds)/nsPerSec,
+			cpu.CPUNum,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			waitingSecondsTotal,
+			prometheus.CounterValue,
+			float64(cpu.WaitingNanoseconds)/nsPerSec,
+			cpu.CPUNum,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			timeslicesTotal,
+			prometheus.CounterValue,
+			float64(cpu.RunTimeslices),
+			cpu.CPUNum,
+		)
+	}
+
+	return nil
+}
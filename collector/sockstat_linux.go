@@ -0,0 +1,166 @@
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/procfs"
+	"github.com/rs/zerolog/log"
+	"os"
+)
+
+const (
+	sockStatSubsystem = "sockstat"
+)
+
+// Used for calculating the total memory bytes on TCP and UDP.
+var pageSize = os.Getpagesize()
+
+type sockStatCollector struct {
+	fs procfs.FS
+}
+
+func init() {
+	registerCollector(sockStatSubsystem, defaultEnabled, NewSockStatCollector)
+}
+
+// NewSockStatCollector returns a new Collector exposing socket stats.
+func NewSockStatCollector() (Collector, error) {
+	fs, err := procfs.NewFS(*procPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open procfs: %w", err)
+	}
+	return &sockStatCollector{
+		fs: fs,
+	}, nil
+}
+
+func (c *sockStatCollector) Update(ch chan<- prometheus.Metric) error {
+	// If IPv4 and/or IPv6 are disabled on this kernel, handle it gracefully.
+	stat4, err := c.fs.NetSockstat()
+	switch {
+	case err == nil:
+	case errors.Is(err, os.ErrNotExist):
+		log.Debug().Msg("IPv4 sockstat statistics not found, skipping")
+	default:
+		return fmt.Errorf("failed to get IPv4 sockstat data: %w", err)
+	}
+
+	stat6, err := c.fs.NetSockstat6()
+	switch {
+	case err == nil:
+	case errors.Is(err, os.ErrNotExist):
+		log.Debug().Msg("IPv6 sockstat statistics not found, skipping")
+	default:
+		return fmt.Errorf("failed to get IPv6 sockstat data: %w", err)
+	}
+
+	stats := []struct {
+		isIPv6 bool
+		stat   *procfs.NetSockstat
+	}{
+		{
+			stat: stat4,
+		},
+		{
+			isIPv6: true,
+			stat:   stat6,
+		},
+	}
+
+	for _, s := range stats {
+		c.update(ch, s.isIPv6, s.stat)
+	}
+
+	return nil
+}
+
+func (c *sockStatCollector) update(ch chan<- prometheus.Metric, isIPv6 bool, s *procfs.NetSockstat) {
+	if s == nil {
+		// IPv6 disabled or similar; nothing to do.
+		return
+	}
+
+	// Only sockstat has the "sockets: used" line, it counts the sockets of
+	// all address families.
+	if !isIPv6 && s.Used != nil {
+		ch <- prometheus.MustNewConstMetric(
+			prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, sockStatSubsystem, "sockets_used"),
+				"Number of sockets in use.",
+				nil,
+				nil,
+			),
+			prometheus.GaugeValue,
+			float64(*s.Used),
+		)
+	}
+
+	// A name and optional value for a sockstat metric.
+	type ssPair struct {
+		name string
+		v    *int
+	}
+
+	// Name the metrics after the fields of the file, e.g. TCP_tw or FRAG_memory.
+	for _, p := range s.Protocols {
+		pairs := []ssPair{
+			{
+				name: "inuse",
+				v:    &p.InUse,
+			},
+			{
+				name: "orphan",
+				v:    p.Orphan,
+			},
+			{
+				name: "tw",
+				v:    p.TW,
+			},
+			{
+				name: "alloc",
+				v:    p.Alloc,
+			},
+			{
+				name: "mem",
+				v:    p.Mem,
+			},
+			{
+				name: "memory",
+				v:    p.Memory,
+			},
+		}
+
+		// Also export mem_bytes values for sockets which have a mem value
+		// stored in pages.
+		if p.Mem != nil {
+			v := *p.Mem * pageSize
+			pairs = append(pairs, ssPair{
+				name: "mem_bytes",
+				v:    &v,
+			})
+		}
+
+		for _, pair := range pairs {
+			if pair.v == nil {
+				// This value is not set for this protocol; nothing to do.
+				continue
+			}
+
+			ch <- prometheus.MustNewConstMetric(
+				prometheus.NewDesc(
+					prometheus.BuildFQName(
+						namespace,
+						sockStatSubsystem,
+						fmt.Sprintf("%s_%s", p.Protocol, pair.name),
+					),
+					fmt.Sprintf("Number of %s sockets in state %s.", p.Protocol, pair.name),
+					nil,
+					nil,
+				),
+				prometheus.GaugeValue,
+				float64(*pair.v),
+			)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package collector
+
+import (
+	"flag"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/procfs"
+	"strconv"
+)
+
+var (
+	softirqsAggregateCPUs = flag.Bool("collector.softirqs.aggregate-cpus", false, "Sum the softirqs of all CPUs instead of exporting one series per CPU.")
+)
+
+type softirqsCollector struct {
+	fs            procfs.FS
+	desc          typedDesc
+	aggregateDesc typedDesc
+}
+
+func init() {
+	registerCollector("softirqs", defaultDisabled, NewSoftirqsCollector)
+}
+
+// NewSoftirqsCollector returns a new Collector exposing softirq stats.
+func NewSoftirqsCollector() (Collector, error) {
+	fs, err := procfs.NewFS(*procPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open procfs: %w", err)
+	}
+	return &softirqsCollector{
+		fs: fs,
+		desc: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "softirqs", "total"),
+			"Number of softirqs handled per CPU and type.",
+			[]string{"cpu", "type"}, nil,
+		), prometheus.CounterValue},
+		aggregateDesc: typedDesc{prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "softirqs", "total"),
+			"Number of softirqs handled per type, summed over all CPUs.",
+			[]string{"type"}, nil,
+		), prometheus.CounterValue},
+	}, nil
+}
+
+func (c *softirqsCollector) Update(ch chan<- prometheus.Metric) error {
+	softirqs, err := c.fs.Softirqs()
+	if err != nil {
+		return fmt.Errorf("couldn't get softirqs: %w", err)
+	}
+
+	for _, s := range []struct {
+		name   string
+		values []uint64
+	}{
+		{"HI", softirqs.Hi},
+		{"TIMER", softirqs.Timer},
+		{"NET_TX", softirqs.NetTx},
+		{"NET_RX", softirqs.NetRx},
+		{"BLOCK", softirqs.Block},
+		{"IRQ_POLL", softirqs.IRQPoll},
+		{"TASKLET", softirqs.Tasklet},
+		{"SCHED", softirqs.Sched},
+		{"HRTIMER", softirqs.HRTimer},
+		{"RCU", softirqs.RCU},
+	} {
+		if *softirqsAggregateCPUs {
+			var sum uint64
+			for _, v := range s.values {
+				sum += v
+			}
+			ch <- c.aggregateDesc.mustNewConstMetric(float64(sum), s.name)
+			continue
+		}
+		for cpu, v := range s.values {
+			ch <- c.desc.mustNewConstMetric(float64(v), strconv.Itoa(cpu), s.name)
+		}
+	}
+	return nil
+}
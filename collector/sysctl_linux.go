@@ -0,0 +1,134 @@
+package collector
+
+import (
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/procfs"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	sysctlSubsystem = "sysctl"
+)
+
+var (
+	sysctlInclude     = stringSliceFlag("collector.sysctl.include", "Numeric sysctl to export as gauge, e.g. net.core.somaxconn. Append :name1,name2 to name the values of a multi-value sysctl, e.g. net.ipv4.tcp_rmem:min,default,max, which are otherwise exported with an index label. Repeatable.")
+	sysctlIncludeInfo = stringSliceFlag("collector.sysctl.include-info", "Sysctl to export as info metric with its value as label, e.g. kernel.core_pattern. Repeatable.")
+
+	sysctlInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+)
+
+// sysctlEntry is a configured sysctl and the names of its values, if given.
+type sysctlEntry struct {
+	name       string
+	valueNames []string
+}
+
+type sysctlCollector struct {
+	fs       procfs.FS
+	gauges   []sysctlEntry
+	infos    []string
+	infoDesc *prometheus.Desc
+}
+
+func init() {
+	registerCollector(sysctlSubsystem, defaultDisabled, NewSysctlCollector)
+}
+
+// NewSysctlCollector returns a new Collector exposing the sysctls selected by
+// --collector.sysctl.include and --collector.sysctl.include-info.
+func NewSysctlCollector() (Collector, error) {
+	fs, err := procfs.NewFS(*procPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open procfs: %w", err)
+	}
+
+	gauges := make([]sysctlEntry, 0, len(*sysctlInclude))
+	for _, include := range *sysctlInclude {
+		name, valueNames, ok := strings.Cut(include, ":")
+		entry := sysctlEntry{name: name}
+		if ok {
+			entry.valueNames = strings.Split(valueNames, ",")
+		}
+		if entry.name == "" {
+			return nil, fmt.Errorf("invalid sysctl %q", include)
+		}
+		gauges = append(gauges, entry)
+	}
+
+	return &sysctlCollector{
+		fs:     fs,
+		gauges: gauges,
+		infos:  *sysctlIncludeInfo,
+		infoDesc: prometheus.NewDesc(prometheus.BuildFQName(namespace, sysctlSubsystem, "info"),
+			"Value of a sysctl, value is always 1.", []string{"name", "value"}, nil,
+		),
+	}, nil
+}
+
+func (c *sysctlCollector) Update(ch chan<- prometheus.Metric) error {
+	if len(c.gauges) == 0 && len(c.infos) == 0 {
+		return ErrNoData
+	}
+
+	for _, entry := range c.gauges {
+		if err := c.updateGauge(ch, entry); err != nil {
+			return err
+		}
+	}
+
+	// SysctlStrings splits the value on whitespace, e.g. kernel.core_pattern
+	// may be a command line, so the fields are joined again.
+	for _, name := range c.infos {
+		fields, err := c.fs.SysctlStrings(name)
+		if err != nil {
+			return fmt.Errorf("error reading sysctl %s: %w", name, err)
+		}
+		ch <- prometheus.MustNewConstMetric(c.infoDesc, prometheus.GaugeValue, 1, name, strings.Join(fields, " "))
+	}
+	return nil
+}
+
+// updateGauge exports a numeric sysctl as node1s_sysctl_<name>. The values of
+// a multi-value sysctl are exported as node1s_sysctl_<name>_<value name> when
+// named and with an index label otherwise.
+func (c *sysctlCollector) updateGauge(ch chan<- prometheus.Metric, entry sysctlEntry) error {
+	// Values can exceed int64, e.g. kernel.shmall, so they are not read
+	// with SysctlInts.
+	fields, err := c.fs.SysctlStrings(entry.name)
+	if err != nil {
+		return fmt.Errorf("error reading sysctl %s: %w", entry.name, err)
+	}
+	values := make([]float64, len(fields))
+	for i, field := range fields {
+		if values[i], err = strconv.ParseFloat(field, 64); err != nil {
+			return fmt.Errorf("sysctl %s is not numeric: %w", entry.name, err)
+		}
+	}
+
+	metricName := sysctlInvalidChars.ReplaceAllString(entry.name, "_")
+	help := fmt.Sprintf("sysctl %s", entry.name)
+
+	switch {
+	case len(entry.valueNames) > 0:
+		if len(entry.valueNames) != len(values) {
+			return fmt.Errorf("sysctl %s has %d values, but %d names are configured", entry.name, len(values), len(entry.valueNames))
+		}
+		for i, valueName := range entry.valueNames {
+			desc := prometheus.NewDesc(prometheus.BuildFQName(namespace, sysctlSubsystem, metricName+"_"+sysctlInvalidChars.ReplaceAllString(valueName, "_")),
+				fmt.Sprintf("sysctl %s, field %d", entry.name, i), nil, nil)
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, values[i])
+		}
+	case len(values) == 1:
+		desc := prometheus.NewDesc(prometheus.BuildFQName(namespace, sysctlSubsystem, metricName), help, nil, nil)
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, values[0])
+	default:
+		desc := prometheus.NewDesc(prometheus.BuildFQName(namespace, sysctlSubsystem, metricName), help, []string{"index"}, nil)
+		for i, value := range values {
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value, strconv.Itoa(i))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package collector
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSysctlInfo(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "sys/kernel"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	const corePattern = "|/usr/lib/systemd/systemd-coredump %P %u %g"
+	if err := os.WriteFile(filepath.Join(root, "sys/kernel/core_pattern"), []byte(corePattern+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	oldProcPath, oldIncludeInfo := *procPath, *sysctlIncludeInfo
+	t.Cleanup(func() { *procPath, *sysctlIncludeInfo = oldProcPath, oldIncludeInfo })
+	*procPath = root
+	*sysctlIncludeInfo = stringSlice{"kernel.core_pattern"}
+
+	c, err := NewSysctlCollector()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch := make(chan prometheus.Metric, 10)
+	if err := c.Update(ch); err != nil {
+		t.Fatal(err)
+	}
+	close(ch)
+
+	var metrics []*dto.Metric
+	for m := range ch {
+		var pb dto.Metric
+		if err := m.Write(&pb); err != nil {
+			t.Fatal(err)
+		}
+		metrics = append(metrics, &pb)
+	}
+	if len(metrics) != 1 {
+		t.Fatalf("want 1 metric, got %d", len(metrics))
+	}
+	labels := map[string]string{}
+	for _, l := range metrics[0].GetLabel() {
+		labels[l.GetName()] = l.GetValue()
+	}
+	if labels["name"] != "kernel.core_pattern" || labels["value"] != corePattern {
+		t.Errorf("unexpected labels: %v", labels)
+	}
+}
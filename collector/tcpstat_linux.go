@@ -0,0 +1,299 @@
+package collector
+
+import (
+	"encoding/binary"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/josharian/native"
+	"github.com/mdlayher/netlink"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/sys/unix"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+type tcpConnectionState int
+
+const (
+	// TCP_ESTABLISHED
+	tcpEstablished tcpConnectionState = iota + 1
+	// TCP_SYN_SENT
+	tcpSynSent
+	// TCP_SYN_RECV
+	tcpSynRecv
+	// TCP_FIN_WAIT1
+	tcpFinWait1
+	// TCP_FIN_WAIT2
+	tcpFinWait2
+	// TCP_TIME_WAIT
+	tcpTimeWait
+	// TCP_CLOSE
+	tcpClose
+	// TCP_CLOSE_WAIT
+	tcpCloseWait
+	// TCP_LAST_ACK
+	tcpLastAck
+	// TCP_LISTEN
+	tcpListen
+	// TCP_CLOSING
+	tcpClosing
+)
+
+const (
+	tcpStatSubsystem = "tcp"
+
+	// SOCK_DIAG_BY_FAMILY from linux/sock_diag.h
+	sockDiagByFamily = 20
+	// sizeof(struct inet_diag_req_v2)
+	inetDiagReqV2Len = 56
+	// sizeof(struct inet_diag_msg)
+	inetDiagMsgLen = 72
+	// tcpStatReceiveBufferLen is the size of the buffer the dump is read
+	// into, like ss(8) the kernel fills up to 32KiB per datagram.
+	tcpStatReceiveBufferLen = 32 << 10
+)
+
+var (
+	tcpStatPorts = flag.String("collector.tcpstat.ports", "", "Comma separated list of local ports to export per-port connection states for, e.g. 80,443.")
+)
+
+type tcpStatCollector struct {
+	ports       map[uint16]string
+	states      *prometheus.Desc
+	portStates  *prometheus.Desc
+	queuedBytes *prometheus.Desc
+}
+
+// tcpStats holds the socket counts of one netlink dump.
+type tcpStats struct {
+	states     map[tcpConnectionState]float64
+	portStates map[uint16]map[tcpConnectionState]float64
+	rxQueued   float64
+	txQueued   float64
+}
+
+func newTCPStats() *tcpStats {
+	return &tcpStats{
+		states:     map[tcpConnectionState]float64{},
+		portStates: map[uint16]map[tcpConnectionState]float64{},
+	}
+}
+
+func init() {
+	registerCollector("tcpstat", defaultDisabled, NewTCPStatCollector)
+}
+
+// NewTCPStatCollector returns a new Collector exposing network stats.
+func NewTCPStatCollector() (Collector, error) {
+	ports, err := parseTCPStatPorts(*tcpStatPorts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse --collector.tcpstat.ports: %w", err)
+	}
+	return &tcpStatCollector{
+		ports: ports,
+		states: prometheus.NewDesc(prometheus.BuildFQName(namespace, tcpStatSubsystem, "connection_states"),
+			"Number of TCP sockets in each state by address family.", []string{"family", "state"}, nil,
+		),
+		portStates: prometheus.NewDesc(prometheus.BuildFQName(namespace, tcpStatSubsystem, "port_connection_states"),
+			"Number of TCP sockets in each state per configured local port by address family.", []string{"family", "port", "state"}, nil,
+		),
+		queuedBytes: prometheus.NewDesc(prometheus.BuildFQName(namespace, tcpStatSubsystem, "queued_bytes"),
+			"Sum of the receive and transmit queue sizes of all non listening TCP sockets by address family.", []string{"family", "queue"}, nil,
+		),
+	}, nil
+}
+
+func parseTCPStatPorts(value string) (map[uint16]string, error) {
+	ports := map[uint16]string{}
+	for _, p := range strings.Split(value, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		port, err := strconv.ParseUint(p, 10, 16)
+		if err != nil {
+			return nil, err
+		}
+		ports[uint16(port)] = p
+	}
+	return ports, nil
+}
+
+func (c *tcpStatCollector) Update(ch chan<- prometheus.Metric) error {
+	conn, err := netlink.Dial(unix.NETLINK_SOCK_DIAG, nil)
+	if err != nil {
+		return fmt.Errorf("couldn't connect netlink: %w", err)
+	}
+	defer conn.Close()
+
+	// Each family is counted on its own, so that a dump failing midway
+	// doesn't leave partial counts.
+	stats := newTCPStats()
+	if err := c.dump(conn, unix.AF_INET, stats); err != nil {
+		return fmt.Errorf("couldn't get tcpstats: %w", err)
+	}
+	c.updateFamily(ch, unix.AF_INET, stats)
+
+	stats6 := newTCPStats()
+	switch err := c.dump(conn, unix.AF_INET6, stats6); {
+	case err == nil:
+		c.updateFamily(ch, unix.AF_INET6, stats6)
+	case errors.Is(err, unix.ENOENT), errors.Is(err, unix.EAFNOSUPPORT):
+		// IPv6 is disabled on this kernel.
+		log.Debug().Err(err).Msg("couldn't get IPv6 tcpstats, skipping")
+	default:
+		log.Warn().Err(err).Msg("couldn't get IPv6 tcpstats, exporting IPv4 only")
+	}
+	return nil
+}
+
+func (c *tcpStatCollector) updateFamily(ch chan<- prometheus.Metric, family uint8, stats *tcpStats) {
+	name := routeFamily(family)
+	for st := tcpEstablished; st <= tcpClosing; st++ {
+		ch <- prometheus.MustNewConstMetric(c.states, prometheus.GaugeValue, stats.states[st], name, st.String())
+	}
+	for port, portName := range c.ports {
+		for st := tcpEstablished; st <= tcpClosing; st++ {
+			ch <- prometheus.MustNewConstMetric(c.portStates, prometheus.GaugeValue, stats.portStates[port][st], name, portName, st.String())
+		}
+	}
+	ch <- prometheus.MustNewConstMetric(c.queuedBytes, prometheus.GaugeValue, stats.rxQueued, name, "rx")
+	ch <- prometheus.MustNewConstMetric(c.queuedBytes, prometheus.GaugeValue, stats.txQueued, name, "tx")
+}
+
+func (c *tcpStatCollector) dump(conn *netlink.Conn, family uint8, stats *tcpStats) error {
+	// struct inet_diag_req_v2: family, protocol, ext, pad, states and an
+	// all zero inet_diag_sockid, asking for the sockets in every state.
+	req := make([]byte, inetDiagReqV2Len)
+	req[0] = family
+	req[1] = unix.IPPROTO_TCP
+	native.Endian.PutUint32(req[4:8], 0xffffffff)
+
+	sent, err := conn.Send(netlink.Message{
+		Header: netlink.Header{
+			Type:  sockDiagByFamily,
+			Flags: netlink.Request | netlink.Dump,
+		},
+		Data: req,
+	})
+	if err != nil {
+		return err
+	}
+
+	// conn.Receive would collect the whole dump, one message per socket,
+	// so the datagrams are read into a single buffer and counted right away.
+	rc, err := conn.SyscallConn()
+	if err != nil {
+		return err
+	}
+	return c.receive(func(buf []byte) (int, error) {
+		var (
+			n       int
+			recvErr error
+		)
+		err := rc.Read(func(fd uintptr) bool {
+			n, _, recvErr = unix.Recvfrom(int(fd), buf, 0)
+			// Wait for the socket to become readable again.
+			return !errors.Is(recvErr, unix.EAGAIN)
+		})
+		if err != nil {
+			return 0, err
+		}
+		return n, recvErr
+	}, sent.Header.Sequence, stats)
+}
+
+// receive reads the datagrams of the dump with sequence number seq using recv
+// and counts the replies, until the kernel signals the end of the dump.
+func (c *tcpStatCollector) receive(recv func([]byte) (int, error), seq uint32, stats *tcpStats) error {
+	buf := make([]byte, tcpStatReceiveBufferLen)
+	for {
+		n, err := recv(buf)
+		if err != nil {
+			return err
+		}
+
+		msgs, err := syscall.ParseNetlinkMessage(buf[:n])
+		if err != nil {
+			return err
+		}
+		for _, m := range msgs {
+			if m.Header.Seq != seq {
+				continue
+			}
+			switch m.Header.Type {
+			case unix.NLMSG_DONE:
+				return nil
+			case unix.NLMSG_ERROR:
+				if len(m.Data) < 4 {
+					return fmt.Errorf("short netlink error: %d bytes", len(m.Data))
+				}
+				if errno := int32(native.Endian.Uint32(m.Data[0:4])); errno != 0 {
+					return syscall.Errno(-errno)
+				}
+				continue
+			}
+			if err := c.count(m.Data, stats); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// count counts a struct inet_diag_msg reply of a dump.
+func (c *tcpStatCollector) count(data []byte, stats *tcpStats) error {
+	if len(data) < inetDiagMsgLen {
+		return fmt.Errorf("short inet_diag_msg: %d bytes", len(data))
+	}
+	state := tcpConnectionState(data[1])
+	// idiag_sport is in network byte order.
+	sport := binary.BigEndian.Uint16(data[4:6])
+	rqueue := native.Endian.Uint32(data[56:60])
+	wqueue := native.Endian.Uint32(data[60:64])
+
+	stats.states[state]++
+	if _, ok := c.ports[sport]; ok {
+		if stats.portStates[sport] == nil {
+			stats.portStates[sport] = map[tcpConnectionState]float64{}
+		}
+		stats.portStates[sport][state]++
+	}
+	// For listening sockets the queues hold the accept backlog, not bytes.
+	if state != tcpListen {
+		stats.rxQueued += float64(rqueue)
+		stats.txQueued += float64(wqueue)
+	}
+	return nil
+}
+
+func (st tcpConnectionState) String() string {
+	switch st {
+	case tcpEstablished:
+		return "established"
+	case tcpSynSent:
+		return "syn_sent"
+	case tcpSynRecv:
+		return "syn_recv"
+	case tcpFinWait1:
+		return "fin_wait1"
+	case tcpFinWait2:
+		return "fin_wait2"
+	case tcpTimeWait:
+		return "time_wait"
+	case tcpClose:
+		return "close"
+	case tcpCloseWait:
+		return "close_wait"
+	case tcpLastAck:
+		return "last_ack"
+	case tcpListen:
+		return "listen"
+	case tcpClosing:
+		return "closing"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,147 @@
+package collector
+
+import (
+	"encoding/binary"
+	"errors"
+	"github.com/josharian/native"
+	"golang.org/x/sys/unix"
+	"io"
+	"reflect"
+	"testing"
+)
+
+// testNetlinkMessage encodes a netlink message with a struct nlmsghdr.
+func testNetlinkMessage(typ uint16, seq uint32, data []byte) []byte {
+	b := make([]byte, unix.NLMSG_HDRLEN+len(data))
+	native.Endian.PutUint32(b[0:4], uint32(len(b)))
+	native.Endian.PutUint16(b[4:6], typ)
+	native.Endian.PutUint16(b[6:8], unix.NLM_F_MULTI)
+	native.Endian.PutUint32(b[8:12], seq)
+	copy(b[unix.NLMSG_HDRLEN:], data)
+	for len(b)%unix.NLMSG_ALIGNTO != 0 {
+		b = append(b, 0)
+	}
+	return b
+}
+
+// testInetDiagMsg encodes a struct inet_diag_msg.
+func testInetDiagMsg(state tcpConnectionState, sport uint16, rqueue, wqueue uint32) []byte {
+	b := make([]byte, inetDiagMsgLen)
+	b[0] = unix.AF_INET
+	b[1] = uint8(state)
+	binary.BigEndian.PutUint16(b[4:6], sport)
+	native.Endian.PutUint32(b[56:60], rqueue)
+	native.Endian.PutUint32(b[60:64], wqueue)
+	return b
+}
+
+// testNetlinkError encodes a struct nlmsgerr, which is followed by the header
+// of the request.
+func testNetlinkError(errno int32) []byte {
+	b := make([]byte, 4+unix.NLMSG_HDRLEN)
+	native.Endian.PutUint32(b[0:4], uint32(errno))
+	return b
+}
+
+func testDatagram(msgs ...[]byte) []byte {
+	var b []byte
+	for _, m := range msgs {
+		b = append(b, m...)
+	}
+	return b
+}
+
+func TestTCPStatReceive(t *testing.T) {
+	const seq = 7
+	done := testNetlinkMessage(unix.NLMSG_DONE, seq, make([]byte, 4))
+	tests := []struct {
+		name      string
+		datagrams [][]byte
+		want      *tcpStats
+		err       error
+	}{
+		{
+			name: "multipart dump",
+			datagrams: [][]byte{
+				testDatagram(
+					testNetlinkMessage(sockDiagByFamily, seq, testInetDiagMsg(tcpEstablished, 22, 10, 5)),
+					testNetlinkMessage(sockDiagByFamily, seq, testInetDiagMsg(tcpListen, 22, 128, 0)),
+				),
+				testDatagram(
+					// Replies to another request are skipped.
+					testNetlinkMessage(sockDiagByFamily, seq+1, testInetDiagMsg(tcpEstablished, 80, 1, 1)),
+					testNetlinkMessage(sockDiagByFamily, seq, testInetDiagMsg(tcpTimeWait, 40000, 0, 0)),
+				),
+				done,
+			},
+			want: &tcpStats{
+				states:     map[tcpConnectionState]float64{tcpEstablished: 1, tcpListen: 1, tcpTimeWait: 1},
+				portStates: map[uint16]map[tcpConnectionState]float64{22: {tcpEstablished: 1, tcpListen: 1}},
+				rxQueued:   10,
+				txQueued:   5,
+			},
+		},
+		{
+			name:      "empty dump",
+			datagrams: [][]byte{done},
+			want:      newTCPStats(),
+		},
+		{
+			name: "ack",
+			datagrams: [][]byte{
+				testDatagram(testNetlinkMessage(unix.NLMSG_ERROR, seq, testNetlinkError(0)), done),
+			},
+			want: newTCPStats(),
+		},
+		{
+			name: "error reply",
+			datagrams: [][]byte{
+				testNetlinkMessage(unix.NLMSG_ERROR, seq, testNetlinkError(-int32(unix.ENOENT))),
+			},
+			err: unix.ENOENT,
+		},
+		{
+			name: "short reply",
+			datagrams: [][]byte{
+				testNetlinkMessage(sockDiagByFamily, seq, make([]byte, 8)),
+			},
+			err: errors.New("short inet_diag_msg: 8 bytes"),
+		},
+		{
+			name: "dump without done",
+			datagrams: [][]byte{
+				testNetlinkMessage(sockDiagByFamily, seq, testInetDiagMsg(tcpEstablished, 22, 0, 0)),
+			},
+			err: io.EOF,
+		},
+	}
+
+	c := &tcpStatCollector{ports: map[uint16]string{22: "22"}}
+	for _, tt := range tests {
+		datagrams := tt.datagrams
+		recv := func(buf []byte) (int, error) {
+			if len(datagrams) == 0 {
+				return 0, io.EOF
+			}
+			n := copy(buf, datagrams[0])
+			datagrams = datagrams[1:]
+			return n, nil
+		}
+
+		stats := newTCPStats()
+		err := c.receive(recv, seq, stats)
+		if tt.err != nil {
+			if err == nil || (!errors.Is(err, tt.err) && err.Error() != tt.err.Error()) {
+				t.Errorf("%s: want error %v, got %v", tt.name, tt.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(stats, tt.want) {
+			t.Errorf("%s: want %+v, got %+v", tt.name, tt.want, stats)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/procfs/xfs"
+	"github.com/rs/zerolog/log"
+	"os"
+)
+
+const (
+	xfsSubsystem = "xfs"
+)
+
+// xfsMetric is a statistic of the xfs stats files exported per filesystem.
+type xfsMetric struct {
+	name      string
+	help      string
+	valueType prometheus.ValueType
+	value     func(s *xfs.Stats) float64
+}
+
+var xfsMetrics = []xfsMetric{
+	{"extent_allocation_extents_allocated_total", "Number of extents allocated for a filesystem.", prometheus.CounterValue,
+		func(s *xfs.Stats) float64 { return float64(s.ExtentAllocation.ExtentsAllocated) }},
+	{"extent_allocation_blocks_allocated_total", "Number of blocks allocated for a filesystem.", prometheus.CounterValue,
+		func(s *xfs.Stats) float64 { return float64(s.ExtentAllocation.BlocksAllocated) }},
+	{"extent_allocation_extents_freed_total", "Number of extents freed for a filesystem.", prometheus.CounterValue,
+		func(s *xfs.Stats) float64 { return float64(s.ExtentAllocation.ExtentsFreed) }},
+	{"extent_allocation_blocks_freed_total", "Number of blocks freed for a filesystem.", prometheus.CounterValue,
+		func(s *xfs.Stats) float64 { return float64(s.ExtentAllocation.BlocksFreed) }},
+	{"block_mapping_reads_total", "Number of block map for read operations for a filesystem.", prometheus.CounterValue,
+		func(s *xfs.Stats) float64 { return float64(s.BlockMapping.Reads) }},
+	{"block_mapping_writes_total", "Number of block map for write operations for a filesystem.", prometheus.CounterValue,
+		func(s *xfs.Stats) float64 { return float64(s.BlockMapping.Writes) }},
+	{"block_mapping_unmaps_total", "Number of block unmaps (deletes) for a filesystem.", prometheus.CounterValue,
+		func(s *xfs.Stats) float64 { return float64(s.BlockMapping.Unmaps) }},
+	{"directory_operation_lookup_total", "Number of file name directory lookups which miss the operating systems directory name lookup cache.", prometheus.CounterValue,
+		func(s *xfs.Stats) float64 { return float64(s.DirectoryOperation.Lookups) }},
+	{"directory_operation_create_total", "Number of times a new directory entry was created for a filesystem.", prometheus.CounterValue,
+		func(s *xfs.Stats) float64 { return float64(s.DirectoryOperation.Creates) }},
+	{"directory_operation_remove_total", "Number of times an existing directory entry was removed for a filesystem.", prometheus.CounterValue,
+		func(s *xfs.Stats) float64 { return float64(s.DirectoryOperation.Removes) }},
+	{"directory_operation_getdents_total", "Number of times the directory getdents operation was performed for a filesystem.", prometheus.CounterValue,
+		func(s *xfs.Stats) float64 { return float64(s.DirectoryOperation.Getdents) }},
+	{"inode_operation_attempts_total", "Number of times the OS looked for an XFS inode in the inode cache.", prometheus.CounterValue,
+		func(s *xfs.Stats) float64 { return float64(s.InodeOperation.Attempts) }},
+	{"inode_operation_found_total", "Number of times the OS looked for and found an XFS inode in the inode cache.", prometheus.CounterValue,
+		func(s *xfs.Stats) float64 { return float64(s.InodeOperation.Found) }},
+	{"inode_operation_missed_total", "Number of times the OS looked for an XFS inode in the cache, but did not find it.", prometheus.CounterValue,
+		func(s *xfs.Stats) float64 { return float64(s.InodeOperation.Missed) }},
+	{"inode_operation_reclaims_total", "Number of times the OS reclaimed an XFS inode from the inode cache to free memory.", prometheus.CounterValue,
+		func(s *xfs.Stats) float64 { return float64(s.InodeOperation.Reclaims) }},
+	{"log_operation_writes_total", "Number of log buffer writes to disk.", prometheus.CounterValue,
+		func(s *xfs.Stats) float64 { return float64(s.LogOperation.Writes) }},
+	{"log_operation_blocks_total", "Number of log blocks written to disk.", prometheus.CounterValue,
+		func(s *xfs.Stats) float64 { return float64(s.LogOperation.Blocks) }},
+	{"log_operation_force_total", "Number of times the in-core log was forced to disk.", prometheus.CounterValue,
+		func(s *xfs.Stats) float64 { return float64(s.LogOperation.Force) }},
+	{"read_calls_total", "Number of read(2) system calls made to files in a filesystem.", prometheus.CounterValue,
+		func(s *xfs.Stats) float64 { return float64(s.ReadWrite.Read) }},
+	{"write_calls_total", "Number of write(2) system calls made to files in a filesystem.", prometheus.CounterValue,
+		func(s *xfs.Stats) float64 { return float64(s.ReadWrite.Write) }},
+	{"read_bytes_total", "Number of bytes read from files in a filesystem.", prometheus.CounterValue,
+		func(s *xfs.Stats) float64 { return float64(s.ExtendedPrecision.ReadBytes) }},
+	{"write_bytes_total", "Number of bytes written to files in a filesystem.", prometheus.CounterValue,
+		func(s *xfs.Stats) float64 { return float64(s.ExtendedPrecision.WriteBytes) }},
+	{"flush_bytes_total", "Number of bytes written by log flushes in a filesystem.", prometheus.CounterValue,
+		func(s *xfs.Stats) float64 { return float64(s.ExtendedPrecision.FlushBytes) }},
+	{"vnode_active", "Number of vnodes not on free lists for a filesystem.", prometheus.GaugeValue,
+		func(s *xfs.Stats) float64 { return float64(s.Vnode.Active) }},
+}
+
+type xfsCollector struct {
+	fs    xfs.FS
+	descs []*prometheus.Desc
+}
+
+func init() {
+	registerCollector(xfsSubsystem, defaultEnabled, NewXFSCollector)
+}
+
+// NewXFSCollector returns a new Collector exposing XFS statistics.
+// Docs from https://xfs.org/index.php/Runtime_Stats
+func NewXFSCollector() (Collector, error) {
+	fs, err := xfs.NewFS(*procPath, *sysPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sysfs: %w", err)
+	}
+
+	descs := make([]*prometheus.Desc, len(xfsMetrics))
+	for i, m := range xfsMetrics {
+		descs[i] = prometheus.NewDesc(prometheus.BuildFQName(namespace, xfsSubsystem, m.name), m.help, []string{"device"}, nil)
+	}
+	return &xfsCollector{fs: fs, descs: descs}, nil
+}
+
+func (c *xfsCollector) Update(ch chan<- prometheus.Metric) error {
+	stats, err := c.fs.SysStats()
+	if err != nil {
+		return fmt.Errorf("failed to retrieve XFS stats: %w", err)
+	}
+
+	// Kernels before 4.4 only have /proc/fs/xfs/stat, which holds the sum
+	// over all XFS filesystems. It is exported with an empty device label.
+	if len(stats) == 0 {
+		s, err := c.fs.ProcStat()
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				log.Debug().Msg("xfs statistics not found, skipping")
+				return ErrNoData
+			}
+			return fmt.Errorf("failed to retrieve XFS stats: %w", err)
+		}
+		stats = append(stats, s)
+	}
+
+	for _, s := range stats {
+		for i, m := range xfsMetrics {
+			ch <- prometheus.MustNewConstMetric(c.descs[i], m.valueType, m.value(s), s.Name)
+		}
+	}
+	return nil
+}